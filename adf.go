@@ -0,0 +1,175 @@
+package main
+
+import (
+	"strings"
+)
+
+// adfNode is a node of an Atlassian Document Format document, which is how
+// version 3 of the Jira Cloud REST API represents rich text.
+type adfNode struct {
+	Type    string                 `json:"type"`
+	Version int                    `json:"version,omitempty"`
+	Attrs   map[string]interface{} `json:"attrs,omitempty"`
+	Content []adfNode              `json:"content,omitempty"`
+	Text    string                 `json:"text,omitempty"`
+	Marks   []adfMark              `json:"marks,omitempty"`
+}
+
+type adfMark struct {
+	Type  string                 `json:"type"`
+	Attrs map[string]interface{} `json:"attrs,omitempty"`
+}
+
+var adfSpanMarks = map[spanKind]string{
+	spanStrong: "strong",
+	spanEm:     "em",
+	spanStrike: "strike",
+	spanCode:   "code",
+}
+
+// adfText renders text with marks, turning line breaks into hardBreak nodes.
+func adfText(text string, marks []adfMark) []adfNode {
+	nodes := make([]adfNode, 0)
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			nodes = append(nodes, adfNode{Type: "hardBreak"})
+		}
+		if line != "" {
+			nodes = append(nodes, adfNode{Type: "text", Text: line, Marks: marks})
+		}
+	}
+	return nodes
+}
+
+func renderADFInline(spans []span, marks []adfMark) []adfNode {
+	nodes := make([]adfNode, 0)
+	for _, sp := range spans {
+		// Copy, so that siblings don't share a backing array.
+		spanMarks := append([]adfMark{}, marks...)
+
+		switch sp.kind {
+		case spanText:
+			nodes = append(nodes, adfText(sp.text, marks)...)
+		case spanCode:
+			nodes = append(nodes, adfText(sp.text, append(spanMarks, adfMark{Type: "code"}))...)
+		case spanLink:
+			link := adfMark{Type: "link", Attrs: map[string]interface{}{"href": sp.url}}
+			nodes = append(nodes, renderADFInline(sp.children, append(spanMarks, link))...)
+		case spanImage:
+			// Images in ADF must be uploaded media, so link to them instead.
+			link := adfMark{Type: "link", Attrs: map[string]interface{}{"href": sp.url}}
+			label := sp.text
+			if label == "" {
+				label = sp.url
+			}
+			nodes = append(nodes, adfText(label, append(spanMarks, link))...)
+		default:
+			mark := adfMark{Type: adfSpanMarks[sp.kind]}
+			nodes = append(nodes, renderADFInline(sp.children, append(spanMarks, mark))...)
+		}
+	}
+	return nodes
+}
+
+func adfParagraph(text string, inline inlineParser) adfNode {
+	return adfNode{Type: "paragraph", Content: renderADFInline(inline(text), nil)}
+}
+
+// renderADFList renders the run of list items starting at blocks[i] at the
+// given nesting level, returning the list and the index of the first block
+// after it.
+func renderADFList(blocks []block, i int, level int, inline inlineParser) (adfNode, int) {
+	list := adfNode{Type: "bulletList"}
+	if blocks[i].ordered {
+		list.Type = "orderedList"
+	}
+
+	for i < len(blocks) && blocks[i].kind == blockListItem && blocks[i].level >= level {
+		if blocks[i].level == level {
+			list.Content = append(list.Content, adfNode{
+				Type:    "listItem",
+				Content: []adfNode{adfParagraph(blocks[i].text, inline)},
+			})
+			i++
+			continue
+		}
+
+		if len(list.Content) == 0 {
+			list.Content = append(list.Content, adfNode{Type: "listItem"})
+		}
+		var nested adfNode
+		nested, i = renderADFList(blocks, i, level+1, inline)
+		last := &list.Content[len(list.Content)-1]
+		last.Content = append(last.Content, nested)
+	}
+	return list, i
+}
+
+// renderADF renders blocks as an ADF document.
+func renderADF(blocks []block, inline inlineParser) adfNode {
+	doc := adfNode{Type: "doc", Version: 1, Content: make([]adfNode, 0)}
+
+	for i := 0; i < len(blocks); {
+		b := blocks[i]
+		switch b.kind {
+		case blockParagraph:
+			doc.Content = append(doc.Content, adfParagraph(b.text, inline))
+		case blockHeading:
+			doc.Content = append(doc.Content, adfNode{
+				Type:    "heading",
+				Attrs:   map[string]interface{}{"level": b.level},
+				Content: renderADFInline(inline(b.text), nil),
+			})
+		case blockListItem:
+			var list adfNode
+			list, i = renderADFList(blocks, i, 1, inline)
+			doc.Content = append(doc.Content, list)
+			continue
+		case blockCode:
+			code := adfNode{Type: "codeBlock"}
+			if b.lang != "" {
+				code.Attrs = map[string]interface{}{"language": b.lang}
+			}
+			if b.text != "" {
+				code.Content = []adfNode{{Type: "text", Text: b.text}}
+			}
+			doc.Content = append(doc.Content, code)
+		case blockQuote:
+			doc.Content = append(doc.Content, adfNode{
+				Type:    "blockquote",
+				Content: []adfNode{adfParagraph(b.text, inline)},
+			})
+		case blockRule:
+			doc.Content = append(doc.Content, adfNode{Type: "rule"})
+		case blockTableRow:
+			table := adfNode{Type: "table"}
+			for ; i < len(blocks) && blocks[i].kind == blockTableRow; i++ {
+				cellType := "tableCell"
+				if blocks[i].header {
+					cellType = "tableHeader"
+				}
+				row := adfNode{Type: "tableRow"}
+				for _, cell := range blocks[i].cells {
+					row.Content = append(row.Content, adfNode{
+						Type:    cellType,
+						Content: []adfNode{adfParagraph(cell, inline)},
+					})
+				}
+				table.Content = append(table.Content, row)
+			}
+			doc.Content = append(doc.Content, table)
+			continue
+		}
+		i++
+	}
+	return doc
+}
+
+// descriptionToADF converts a rendered description, in the given format, to
+// an ADF document.
+func descriptionToADF(description string, format string) adfNode {
+	if format == "markdown" {
+		return renderADF(parseMarkdown(description), parseMarkdownInline)
+	}
+	return renderADF(parseWiki(description), parseWikiInline)
+}
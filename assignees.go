@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// rosterEntry is a member of the team tickets are distributed across. A
+// roster file is a JSON list of these.
+type rosterEntry struct {
+	User   string `json:"user"`
+	Weight int    `json:"weight"`
+}
+
+// assigner hands out assignees for tickets which don't name their own.
+type assigner struct {
+	roster   []rosterEntry
+	weighted bool
+	// next is the roster index to hand out next when round-robin.
+	next int
+	// current holds each entry's running credit when weighted.
+	current []int
+}
+
+func loadRoster(rosterFilePath string) ([]rosterEntry, error) {
+	data, err := ioutil.ReadFile(rosterFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	roster := make([]rosterEntry, 0)
+	err = json.Unmarshal(data, &roster)
+	return roster, err
+}
+
+// newAssigner builds an assigner from --assignees or --roster, or returns nil
+// if neither was given.
+func newAssigner() (*assigner, error) {
+	roster := make([]rosterEntry, 0)
+	if *rosterFilePath != "" {
+		var err error
+		roster, err = loadRoster(*rosterFilePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, user := range *assignees {
+		for _, name := range strings.Split(user, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				roster = append(roster, rosterEntry{User: name, Weight: 1})
+			}
+		}
+	}
+	if len(roster) == 0 {
+		return nil, nil
+	}
+
+	for i := range roster {
+		if roster[i].Weight < 0 {
+			return nil, fmt.Errorf("roster weight for %s must not be negative", roster[i].User)
+		}
+		if roster[i].Weight == 0 {
+			roster[i].Weight = 1
+		}
+	}
+
+	return &assigner{
+		roster:   roster,
+		weighted: *assignStrategy == "weighted",
+		current:  make([]int, len(roster)),
+	}, nil
+}
+
+// Next returns the next assignee. Weighted distribution uses smooth weighted
+// round-robin, so that heavier entries are spread out rather than bunched.
+func (a *assigner) Next() string {
+	if !a.weighted {
+		user := a.roster[a.next].User
+		a.next = (a.next + 1) % len(a.roster)
+		return user
+	}
+
+	total := 0
+	best := 0
+	for i, entry := range a.roster {
+		a.current[i] += entry.Weight
+		total += entry.Weight
+		if a.current[i] > a.current[best] {
+			best = i
+		}
+	}
+	a.current[best] -= total
+	return a.roster[best].User
+}
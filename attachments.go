@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// attacher is implemented by trackers which can upload files to issues.
+type attacher interface {
+	AttachFile(key string, filePath string) error
+}
+
+// attachFiles uploads the ticket's attachments to the issue. Relative paths
+// are taken relative to the tickets file.
+func attachFiles(tracker Tracker, run *Run, issue Issue, created CreatedIssue) error {
+	if len(issue.Ticket.Attachments) == 0 {
+		return nil
+	}
+
+	a, ok := tracker.(attacher)
+	if !ok {
+		return fmt.Errorf("backend %s does not support attachments", *backend)
+	}
+
+	for _, filePath := range issue.Ticket.Attachments {
+		if !filepath.IsAbs(filePath) {
+			filePath = filepath.Join(filepath.Dir(*ticketsFilePath), filePath)
+		}
+		if err := a.AttachFile(created.Key, filePath); err != nil {
+			return err
+		}
+		fmt.Printf("Attached %s to %s\n", filepath.Base(filePath), created.Key)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"os/user"
+	"path"
+	"time"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	auditLogPath = kingpin.Flag(
+		"audit-log",
+		"Path of the audit log every run is appended to. Defaults to audit.log in the --state-dir.",
+	).String()
+)
+
+// auditEntry is a line of the audit log, recording who did what, and when.
+type auditEntry struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	// User is the local user, and Login who they logged into the tracker
+	// as.
+	User        string   `json:"user"`
+	Login       string   `json:"login,omitempty"`
+	Backend     string   `json:"backend"`
+	Run         string   `json:"run"`
+	Epic        string   `json:"epic,omitempty"`
+	TicketsFile string   `json:"tickets_file,omitempty"`
+	TicketsHash string   `json:"tickets_hash,omitempty"`
+	Keys        []string `json:"keys"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// hashFile is the SHA-256 of the file, or nothing if it can't be read
+// again, as with stdin.
+func hashFile(p string) string {
+	f, err := os.Open(p)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// newAuditEntry starts an entry for the action on the run, by the current
+// user, logged into the tracker as trackerLogin.
+func newAuditEntry(action string, run *Run, keys []string, runErr error) auditEntry {
+	entry := auditEntry{
+		Time:    time.Now().UTC(),
+		Action:  action,
+		Backend: run.Backend,
+		Run:     run.ID,
+		Epic:    run.Epic,
+		Keys:    keys,
+	}
+	if u, err := user.Current(); err == nil {
+		entry.User = u.Username
+	}
+	entry.Login = trackerLogin
+	if runErr != nil {
+		entry.Error = runErr.Error()
+	}
+	return entry
+}
+
+// createdKeys are the keys of the issues the run created.
+func createdKeys(created []CreatedIssue) []string {
+	keys := make([]string, len(created))
+	for i, issue := range created {
+		keys[i] = issue.Key
+	}
+	return keys
+}
+
+// appendAudit appends the entry to the audit log, as a line of JSON. The
+// log is only ever appended to.
+func appendAudit(entry auditEntry) error {
+	p := *auditLogPath
+	if p == "" {
+		if err := os.MkdirAll(*stateDir, 0755); err != nil {
+			return err
+		}
+		p = path.Join(*stateDir, "audit.log")
+	}
+
+	data, err := json.Marshal(&entry)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
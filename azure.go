@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const adoAPIVersion = "api-version=7.0"
+
+var (
+	adoURL = kingpin.Flag(
+		"ado-url",
+		"Azure DevOps organization URL (e.g. https://dev.azure.com/my-org), for the azure backend.",
+	).String()
+	adoWorkItemType = kingpin.Flag(
+		"ado-work-item-type",
+		"Type of work item to create, for the azure backend.",
+	).Default("User Story").String()
+	adoFields = kingpin.Flag(
+		"ado-field",
+		"Copy a ticket param onto a work item field, as param=Field.Reference.Name. May be repeated.",
+	).StringMap()
+)
+
+// azureTracker creates work items in Azure Boards. Tickets name their Azure
+// DevOps project in the project field, and the epic is the ID of a Feature or
+// Epic work item, which each created work item is parented to.
+type azureTracker struct {
+	*restClient
+	workItemType string
+	fields       map[string]string
+}
+
+type adoWorkItem struct {
+	ID     int                    `json:"id"`
+	URL    string                 `json:"url"`
+	Fields map[string]interface{} `json:"fields"`
+	Links  struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"_links"`
+}
+
+type adoPatch struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// adoLinkTypes maps the kinds of link known to Tracker onto Azure Boards
+// relation types, as seen from the "from" work item.
+var adoLinkTypes = map[string]string{
+	linkRelates: "System.LinkTypes.Related",
+	linkBlocks:  "System.LinkTypes.Dependency-Forward",
+}
+
+func init() {
+	registerTracker("azure", newAzureTracker)
+}
+
+func newAzureTracker() Tracker {
+	creds, err := trackerCreds()
+	if err != nil {
+		panic(err)
+	}
+
+	token := base64.StdEncoding.EncodeToString([]byte(creds.User + ":" + creds.Password))
+	header := make(http.Header)
+	header.Set("Authorization", "Basic "+token)
+	header.Set("Content-Type", "application/json-patch+json")
+	return &azureTracker{
+		restClient:   newRESTClient(*adoURL, header),
+		workItemType: *adoWorkItemType,
+		fields:       *adoFields,
+	}
+}
+
+// adoClosedStates are the work item states, across the default process
+// templates, in which work is finished.
+var adoClosedStates = map[string]bool{
+	"Closed":  true,
+	"Done":    true,
+	"Removed": true,
+}
+
+func (t *azureTracker) ResolveEpic(name string) (*Epic, error) {
+	id, err := strconv.Atoi(name)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a work item ID", name)
+	}
+
+	var item adoWorkItem
+	err = t.do("GET", fmt.Sprintf("/_apis/wit/workitems/%d?%s", id, adoAPIVersion), nil, &item)
+	if err != nil {
+		return nil, err
+	}
+
+	itemType, _ := item.Fields["System.WorkItemType"].(string)
+	if itemType != "Feature" && itemType != "Epic" {
+		return nil, &notEpicError{Key: name, Type: itemType}
+	}
+
+	state, _ := item.Fields["System.State"].(string)
+	project, _ := item.Fields["System.TeamProject"].(string)
+	return &Epic{
+		ID:      name,
+		Key:     name,
+		URL:     item.URL,
+		Project: project,
+		Status:  state,
+		Closed:  adoClosedStates[state],
+	}, nil
+}
+
+func (t *azureTracker) CreateIssues(epic *Epic, issues []Issue, run *Run) error {
+	for _, issue := range issues {
+		if err := checkStopped(); err != nil {
+			return err
+		}
+		patch := []adoPatch{
+			{Op: "add", Path: "/fields/System.Title", Value: issue.Summary},
+			{Op: "add", Path: "/fields/System.Description", Value: issue.Description},
+			{
+				Op:   "add",
+				Path: "/relations/-",
+				Value: map[string]string{
+					"rel": "System.LinkTypes.Hierarchy-Reverse",
+					"url": epic.URL,
+				},
+			},
+		}
+		if issue.Ticket.Assignee != "" {
+			patch = append(patch, adoPatch{Op: "add", Path: "/fields/System.AssignedTo", Value: issue.Ticket.Assignee})
+		}
+		if issue.Ticket.DueDate != "" {
+			patch = append(patch, adoPatch{Op: "add", Path: "/fields/Microsoft.VSTS.Scheduling.TargetDate", Value: issue.Ticket.DueDate})
+		}
+		if issue.Ticket.StoryPoints != 0 {
+			patch = append(patch, adoPatch{Op: "add", Path: "/fields/Microsoft.VSTS.Scheduling.StoryPoints", Value: issue.Ticket.StoryPoints})
+		}
+		if labels := issue.Ticket.allLabels(); len(labels) > 0 {
+			patch = append(patch, adoPatch{Op: "add", Path: "/fields/System.Tags", Value: strings.Join(labels, "; ")})
+		}
+		for param, field := range t.fields {
+			if value, ok := issue.Ticket.Params[param]; ok {
+				patch = append(patch, adoPatch{Op: "add", Path: "/fields/" + field, Value: value})
+			}
+		}
+
+		path := fmt.Sprintf(
+			"/%s/_apis/wit/workitems/$%s?%s",
+			url.PathEscape(issue.Ticket.Project),
+			url.PathEscape(t.workItemType),
+			adoAPIVersion,
+		)
+		var result adoWorkItem
+		if err := t.do("POST", path, patch, &result); err != nil {
+			return err
+		}
+
+		key := strconv.Itoa(result.ID)
+		printCreated(key, issue.Summary, result.Links.HTML.Href)
+		err := run.Record(CreatedIssue{
+			ID:      key,
+			Key:     key,
+			Project: issue.Ticket.Project,
+			Summary: issue.Summary,
+			Ticket:  issue.Index,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *azureTracker) LinkIssues(from string, to string, kind string) error {
+	rel, ok := adoLinkTypes[kind]
+	if !ok {
+		return fmt.Errorf("unknown link kind %q", kind)
+	}
+
+	patch := []adoPatch{{
+		Op:   "add",
+		Path: "/relations/-",
+		Value: map[string]string{
+			"rel": rel,
+			"url": fmt.Sprintf("%s/_apis/wit/workItems/%s", t.baseURL, to),
+		},
+	}}
+	return t.do("PATCH", fmt.Sprintf("/_apis/wit/workitems/%s?%s", from, adoAPIVersion), patch, nil)
+}
+
+// DeleteIssue moves the work item to the recycle bin.
+func (t *azureTracker) DeleteIssue(key string) error {
+	return t.do("DELETE", fmt.Sprintf("/_apis/wit/workitems/%s?%s", key, adoAPIVersion), nil, nil)
+}
+
+// CloseIssue sets the work item's state to the transition name, such as
+// "Closed" or "Removed".
+func (t *azureTracker) CloseIssue(key string, transition string) error {
+	return t.TransitionIssue(key, transition)
+}
+
+// TransitionIssue sets the work item's state. Azure Boards has no named
+// transitions, so name is the state to move to.
+func (t *azureTracker) TransitionIssue(key string, name string) error {
+	patch := []adoPatch{{Op: "add", Path: "/fields/System.State", Value: name}}
+	return t.do("PATCH", fmt.Sprintf("/_apis/wit/workitems/%s?%s", key, adoAPIVersion), patch, nil)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	batchSize = createCmd.Flag(
+		"batch-size",
+		"Create issues this many at a time, with --batch-pause and --confirm-batches between batches. 0 creates them all at once.",
+	).Default("0").Int()
+	batchPause = createCmd.Flag(
+		"batch-pause",
+		"How long to wait between batches, e.g. 30s.",
+	).Default("0s").Duration()
+	confirmBatches = createCmd.Flag(
+		"confirm-batches",
+		"Ask before creating each batch after the first.",
+	).Bool()
+)
+
+// issueBatch is a batch of issues in the same epic.
+type issueBatch struct {
+	epic   *Epic
+	issues []Issue
+}
+
+// batchIssues splits the issues, in order, into batches of up to
+// --batch-size issues. A batch ends where the next issue is in another
+// epic, so issues are created in the order of the tickets file.
+// Batches share the issues' backing array, so issues can be updated as
+// they're created.
+func batchIssues(issues []Issue) []issueBatch {
+	batches := make([]issueBatch, 0)
+	start := 0
+	for i := range issues {
+		if i == start {
+			continue
+		}
+		if issues[i].Epic.Key != issues[start].Epic.Key || (*batchSize > 0 && i-start == *batchSize) {
+			batches = append(batches, issueBatch{epic: issues[start].Epic, issues: issues[start:i]})
+			start = i
+		}
+	}
+	if start < len(issues) {
+		batches = append(batches, issueBatch{epic: issues[start].Epic, issues: issues[start:]})
+	}
+	return batches
+}
+
+// createBatches creates the issues a batch at a time, pausing or asking to
+// go on between batches.
+func createBatches(tracker Tracker, issues []Issue, run *Run) error {
+	if *confirmBatches && *ticketsFilePath == "-" {
+		return fmt.Errorf("--confirm-batches reads from stdin, so tickets can't be read from it too")
+	}
+	batches := batchIssues(issues)
+	var stdin *bufio.Reader
+	for i, batch := range batches {
+		if i > 0 {
+			fmt.Printf("Created batch %d of %d; %d issue(s) so far.\n", i, len(batches), len(run.Created))
+			if *batchPause > 0 {
+				time.Sleep(*batchPause)
+			}
+			if *confirmBatches {
+				if stdin == nil {
+					stdin = bufio.NewReader(os.Stdin)
+				}
+				fmt.Fprintf(os.Stderr, "Create batch %d of %d (%d issue(s))? [y/N] ", i+1, len(batches), len(batch.issues))
+				answer, _ := stdin.ReadString('\n')
+				if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+					return fmt.Errorf("stopped before batch %d of %d", i+1, len(batches))
+				}
+			}
+		}
+		if err := createChained(tracker, batch, run); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var (
+	boardName = createCmd.Flag(
+		"board",
+		"Show the run's epics on the board with this name, creating it if there's none.",
+	).String()
+	boardType = createCmd.Flag(
+		"board-type",
+		"Type of board --board creates.",
+	).Default("kanban").Enum("kanban", "scrum")
+)
+
+// boarder is implemented by trackers with boards.
+type boarder interface {
+	// ShowOnBoard makes the board include the epics' issues, creating it
+	// if it doesn't exist, and returns where to view it.
+	ShowOnBoard(name string, epics []*Epic) (string, error)
+}
+
+// showOnBoard puts the epics created in on --board.
+func showOnBoard(tracker Tracker, groups []epicGroup) error {
+	b, ok := tracker.(boarder)
+	if !ok {
+		return fmt.Errorf("backend %s does not support boards", *backend)
+	}
+
+	epics := make([]*Epic, len(groups))
+	for i, group := range groups {
+		epics[i] = group.epic
+	}
+	url, err := b.ShowOnBoard(*boardName, epics)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Board %q: %s\n", *boardName, url)
+	return nil
+}
+
+type jiraBoard struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type jiraFilter struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+	JQL  string `json:"jql"`
+}
+
+// ShowOnBoard finds the board by name and adds the epics to its filter, or
+// creates the board, with a filter of its own, for just the epics.
+func (t *jiraTracker) ShowOnBoard(name string, epics []*Epic) (string, error) {
+	board, err := t.findBoard(name)
+	if err != nil {
+		return "", err
+	}
+	if board == nil {
+		board, err = t.createBoard(name, epics)
+	} else {
+		err = t.addToBoard(board, epics)
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/secure/RapidBoard.jspa?rapidView=%d", strings.TrimRight((*jiraURL).String(), "/"), board.ID), nil
+}
+
+func (t *jiraTracker) findBoard(name string) (*jiraBoard, error) {
+	req, err := t.client.NewRequest("GET", "rest/agile/1.0/board?"+url.Values{"name": {name}}.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var page struct {
+		Values []jiraBoard `json:"values"`
+	}
+	resp, err := t.client.Do(req, &page)
+	if err != nil {
+		return nil, jiraAPIRequestErrorHandler(resp, err)
+	}
+	// The name is matched as a substring.
+	for _, board := range page.Values {
+		if board.Name == name {
+			return &board, nil
+		}
+	}
+	return nil, nil
+}
+
+// boardJQL is the JQL of a board showing the epics and their issues.
+func boardJQL(epics []*Epic) string {
+	clauses := make([]string, 0, 2*len(epics))
+	for _, epic := range epics {
+		clauses = append(clauses, "key = "+epic.Key, epicJQL(epic.Key))
+	}
+	return strings.Join(clauses, " OR ")
+}
+
+func (t *jiraTracker) createBoard(name string, epics []*Epic) (*jiraBoard, error) {
+	filter := jiraFilter{Name: name, JQL: boardJQL(epics) + " ORDER BY Rank ASC"}
+	req, err := t.client.NewRequest("POST", "rest/api/2/filter", filter)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.client.Do(req, &filter)
+	if err != nil {
+		return nil, jiraAPIRequestErrorHandler(resp, err)
+	}
+	filterID, err := strconv.Atoi(filter.ID)
+	if err != nil {
+		return nil, fmt.Errorf("filter %s: unexpected id", filter.ID)
+	}
+
+	body := map[string]interface{}{
+		"name":     name,
+		"type":     *boardType,
+		"filterId": filterID,
+		"location": map[string]string{
+			"type":           "project",
+			"projectKeyOrId": epics[0].Project,
+		},
+	}
+	req, err = t.client.NewRequest("POST", "rest/agile/1.0/board", body)
+	if err != nil {
+		return nil, err
+	}
+	board := new(jiraBoard)
+	resp, err = t.client.Do(req, board)
+	if err != nil {
+		return nil, jiraAPIRequestErrorHandler(resp, err)
+	}
+	fmt.Printf("Created %s board %q.\n", *boardType, name)
+	return board, nil
+}
+
+// addToBoard widens the board's filter to take in the epics it doesn't
+// already show.
+func (t *jiraTracker) addToBoard(board *jiraBoard, epics []*Epic) error {
+	req, err := t.client.NewRequest("GET", fmt.Sprintf("rest/agile/1.0/board/%d/configuration", board.ID), nil)
+	if err != nil {
+		return err
+	}
+	var config struct {
+		Filter struct {
+			ID string `json:"id"`
+		} `json:"filter"`
+	}
+	resp, err := t.client.Do(req, &config)
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+
+	req, err = t.client.NewRequest("GET", "rest/api/2/filter/"+config.Filter.ID, nil)
+	if err != nil {
+		return err
+	}
+	var filter jiraFilter
+	resp, err = t.client.Do(req, &filter)
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+
+	missing := make([]*Epic, 0, len(epics))
+	for _, epic := range epics {
+		if !strings.Contains(filter.JQL, epicJQL(epic.Key)) {
+			missing = append(missing, epic)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	// Keep the filter's ordering, which JQL only allows at the end.
+	jql, order := filter.JQL, ""
+	if i := strings.Index(strings.ToUpper(jql), " ORDER BY "); i >= 0 {
+		jql, order = jql[:i], jql[i:]
+	}
+	filter.JQL = fmt.Sprintf("(%s) OR %s%s", jql, boardJQL(missing), order)
+	req, err = t.client.NewRequest("PUT", "rest/api/2/filter/"+filter.ID, filter)
+	if err != nil {
+		return err
+	}
+	resp, err = t.client.Do(req, nil)
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+	fmt.Printf("Added %d epic(s) to board %q.\n", len(missing), board.Name)
+	return nil
+}
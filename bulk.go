@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+import (
+	jira "gopkg.in/andygrunwald/go-jira.v1"
+)
+
+// bulkChunkSize is the maximum number of issues JIRA accepts in a single
+// bulk create request.
+const bulkChunkSize = 50
+
+var errBulkUnsupported = errors.New("bulk issue creation is not supported by this instance")
+
+type bulkCreateRequest struct {
+	IssueUpdates []interface{} `json:"issueUpdates"`
+}
+
+type bulkCreateResponse struct {
+	Issues []jira.Issue      `json:"issues"`
+	Errors []bulkCreateError `json:"errors"`
+}
+
+type bulkCreateError struct {
+	Status              int `json:"status"`
+	FailedElementNumber int `json:"failedElementNumber"`
+	ElementErrors       struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	} `json:"elementErrors"`
+}
+
+func (e bulkCreateError) String() string {
+	messages := append([]string{}, e.ElementErrors.ErrorMessages...)
+	for field, message := range e.ElementErrors.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", field, message))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// bulkCreateIssues creates a chunk of issues with a single request and records
+// those which were created in the run. Issues which JIRA rejected are reported
+// in the returned error.
+func bulkCreateIssues(client *jiraClient, chunk []pendingIssue, run *Run) error {
+	body := bulkCreateRequest{IssueUpdates: make([]interface{}, len(chunk))}
+	for i, p := range chunk {
+		payload, err := p.payload()
+		if err != nil {
+			return err
+		}
+		body.IssueUpdates[i] = payload
+	}
+
+	req, err := client.NewRequest("POST", jiraIssueAPI()+"/bulk", &body)
+	if err != nil {
+		return err
+	}
+
+	var result bulkCreateResponse
+	resp, err := client.Do(req, &result)
+	if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed) {
+		return errBulkUnsupported
+	}
+	if err != nil {
+		// JIRA responds with 400 when any element fails, but still creates
+		// the rest and describes both in the body.
+		if resp == nil || resp.StatusCode != http.StatusBadRequest {
+			return jiraAPIRequestErrorHandler(resp, err)
+		}
+		defer resp.Body.Close()
+		if json.NewDecoder(resp.Body).Decode(&result) != nil {
+			return err
+		}
+	}
+
+	failed := make(map[int]bulkCreateError, len(result.Errors))
+	for _, e := range result.Errors {
+		failed[e.FailedElementNumber] = e
+	}
+
+	created := result.Issues
+	for i, p := range chunk {
+		if _, ok := failed[i]; ok || len(created) == 0 {
+			continue
+		}
+		issue := created[0]
+		created = created[1:]
+
+		printCreated(issue.Key, p.issue.Fields.Summary, issueLink(issue.Key))
+		err := run.Record(CreatedIssue{
+			ID:      issue.ID,
+			Key:     issue.Key,
+			Project: p.ticket.Project,
+			Summary: p.issue.Fields.Summary,
+			Ticket:  p.index,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(failed) > 0 {
+		messages := make([]string, 0, len(failed))
+		for i, p := range chunk {
+			if e, ok := failed[i]; ok {
+				messages = append(messages, fmt.Sprintf("%q: %s", p.issue.Fields.Summary, e))
+			}
+		}
+		return fmt.Errorf("failed to create %d issue(s): %s", len(failed), strings.Join(messages, ", "))
+	}
+	return nil
+}
+
+// submitIssues creates all pending issues, in chunks through the bulk API
+// when it is enabled and available, and one at a time otherwise.
+func submitIssues(client *jiraClient, pending []pendingIssue, run *Run) error {
+	useBulk := *bulk
+	for len(pending) > 0 {
+		if err := checkStopped(); err != nil {
+			return err
+		}
+		if !useBulk {
+			if _, err := createIssue(client, pending[0], run); err != nil {
+				return err
+			}
+			pending = pending[1:]
+			continue
+		}
+
+		n := bulkChunkSize
+		if n > len(pending) {
+			n = len(pending)
+		}
+		err := bulkCreateIssues(client, pending[:n], run)
+		if err == errBulkUnsupported {
+			fmt.Println("Bulk create unavailable, falling back to creating issues one at a time.")
+			useBulk = false
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		pending = pending[n:]
+	}
+	return nil
+}
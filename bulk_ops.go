@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	bulkCmd = kingpin.Command(
+		"bulk",
+		"Change every issue in an epic at once.",
+	)
+	bulkEpic = bulkCmd.Flag(
+		"epic",
+		"Key of the epic whose issues to change.",
+	).Required().String()
+	bulkJQL = bulkCmd.Flag(
+		"jql",
+		"JQL narrowing down which of the epic's issues to change, e.g. 'status = \"To Do\"'.",
+	).String()
+	bulkDryRun = bulkCmd.Flag(
+		"dry-run",
+		"List the issues which would be changed, without changing them.",
+	).Bool()
+
+	bulkTransitionCmd  = bulkCmd.Command("transition", "Move the issues through a workflow transition.")
+	bulkTransitionName = bulkTransitionCmd.Arg("name", "Name of the transition, e.g. \"Ready\".").Required().String()
+	bulkLabelCmd       = bulkCmd.Command("label", "Add labels to the issues.")
+	bulkLabels         = bulkLabelCmd.Arg("labels", "Labels to add.").Required().Strings()
+	bulkAssignCmd      = bulkCmd.Command("assign", "Assign the issues to a user.")
+	bulkAssignee       = bulkAssignCmd.Arg("user", "User to assign the issues to.").Required().String()
+	bulkCommentCmd     = bulkCmd.Command("comment", "Comment on the issues.")
+	bulkCommentBody    = bulkCommentCmd.Arg("body", "Text of the comment.").Required().String()
+)
+
+// labeler is implemented by trackers which can add labels to existing
+// issues.
+type labeler interface {
+	AddLabels(key string, labels []string) error
+}
+
+// issueAssigner is implemented by trackers which can reassign existing
+// issues.
+type issueAssigner interface {
+	AssignIssue(key string, user string) error
+}
+
+// bulkOperation returns what to do to each issue for the bulk subcommand,
+// and what to print once it's done.
+func bulkOperation(tracker Tracker, command string) (func(key string) error, string, error) {
+	switch command {
+	case bulkTransitionCmd.FullCommand():
+		t, ok := tracker.(transitioner)
+		if !ok {
+			return nil, "", fmt.Errorf("backend %s does not support transitions", *backend)
+		}
+		return func(key string) error {
+			return t.TransitionIssue(key, *bulkTransitionName)
+		}, "transitioned to " + *bulkTransitionName, nil
+	case bulkLabelCmd.FullCommand():
+		l, ok := tracker.(labeler)
+		if !ok {
+			return nil, "", fmt.Errorf("backend %s does not support adding labels", *backend)
+		}
+		return func(key string) error {
+			return l.AddLabels(key, *bulkLabels)
+		}, "labelled " + strings.Join(*bulkLabels, ", "), nil
+	case bulkAssignCmd.FullCommand():
+		a, ok := tracker.(issueAssigner)
+		if !ok {
+			return nil, "", fmt.Errorf("backend %s does not support assigning issues", *backend)
+		}
+		return func(key string) error {
+			return a.AssignIssue(key, *bulkAssignee)
+		}, "assigned to " + *bulkAssignee, nil
+	case bulkCommentCmd.FullCommand():
+		c, ok := tracker.(commenter)
+		if !ok {
+			return nil, "", fmt.Errorf("backend %s does not support comments", *backend)
+		}
+		return func(key string) error {
+			return c.AddComment(key, *bulkCommentBody)
+		}, "commented", nil
+	}
+	return nil, "", fmt.Errorf("unknown bulk command %q", command)
+}
+
+func runBulk(command string) {
+	tracker, err := newTracker(*backend)
+	if err != nil {
+		panic(err)
+	}
+	lister, ok := tracker.(epicLister)
+	if !ok {
+		panic(fmt.Errorf("backend %s does not support listing an epic's issues", *backend))
+	}
+	apply, done, err := bulkOperation(tracker, command)
+	if err != nil {
+		panic(err)
+	}
+
+	epic, err := tracker.ResolveEpic(*bulkEpic)
+	if err != nil {
+		panic(err)
+	}
+	issues, err := lister.EpicIssues(epic, *bulkJQL)
+	if err != nil {
+		panic(err)
+	}
+
+	for i, issue := range issues {
+		if *bulkDryRun {
+			fmt.Printf("Would change %s: %s\n", issue.Key, issue.Summary)
+			continue
+		}
+		if err := apply(issue.Key); err != nil {
+			fmt.Printf("Stopped after changing %d of %d issue(s).\n", i, len(issues))
+			panic(err)
+		}
+		fmt.Printf("%s: %s\n", issue.Key, done)
+	}
+	fmt.Printf("%d issue(s) in %s.\n", len(issues), epic.Key)
+}
+
+// AddLabels adds to the issue's labels, keeping those it has.
+func (t *jiraTracker) AddLabels(key string, labels []string) error {
+	add := make([]map[string]string, len(labels))
+	for i, label := range labels {
+		add[i] = map[string]string{"add": label}
+	}
+	return t.updateIssue(key, map[string]interface{}{
+		"update": map[string]interface{}{"labels": add},
+	})
+}
+
+func (t *jiraTracker) AssignIssue(key string, user string) error {
+	user, err := t.ResolveUser(user)
+	if err != nil {
+		return err
+	}
+	return t.updateIssue(key, map[string]interface{}{
+		"fields": map[string]interface{}{"assignee": jiraUser(user)},
+	})
+}
+
+func (t *jiraTracker) updateIssue(key string, update interface{}) error {
+	req, err := t.client.NewRequest("PUT", "rest/api/2/issue/"+key, update)
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Do(req, nil)
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+import (
+	jira "gopkg.in/andygrunwald/go-jira.v1"
+)
+
+// fakeBulkJIRA answers every request with status and body, as go-jira does:
+// decoding the body into v on success, and returning an error otherwise.
+type fakeBulkJIRA struct {
+	status int
+	body   string
+}
+
+func (f fakeBulkJIRA) NewRequest(method, urlStr string, body interface{}) (*http.Request, error) {
+	return http.NewRequest(method, "https://jira.example.com/"+urlStr, nil)
+}
+
+func (f fakeBulkJIRA) Do(req *http.Request, v interface{}) (*jira.Response, error) {
+	resp := &jira.Response{Response: &http.Response{
+		StatusCode: f.status,
+		Body:       ioutil.NopCloser(strings.NewReader(f.body)),
+		Request:    req,
+	}}
+	if f.status >= 300 {
+		return resp, errors.New(http.StatusText(f.status))
+	}
+	return resp, json.NewDecoder(resp.Body).Decode(v)
+}
+
+func TestBulkCreateIssues(t *testing.T) {
+	*backend = "jira"
+	*jiraADF, jiraCloud = false, false
+
+	dir, err := ioutil.TempDir("", "epic-creator-bulk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		err         string
+		unsupported bool
+		created     []string
+	}{
+		{
+			name:    "all created",
+			status:  http.StatusCreated,
+			body:    `{"issues": [{"id": "1", "key": "PROJ-1"}, {"id": "2", "key": "PROJ-2"}, {"id": "3", "key": "PROJ-3"}]}`,
+			created: []string{"PROJ-1 First", "PROJ-2 Second", "PROJ-3 Third"},
+		},
+		{
+			name:   "partial success",
+			status: http.StatusBadRequest,
+			body: `{
+				"issues": [{"id": "1", "key": "PROJ-1"}, {"id": "3", "key": "PROJ-3"}],
+				"errors": [{"status": 400, "failedElementNumber": 1, "elementErrors": {"errors": {"summary": "is bad"}}}]
+			}`,
+			err:     `failed to create 1 issue(s): "Second": summary: is bad`,
+			created: []string{"PROJ-1 First", "PROJ-3 Third"},
+		},
+		{
+			name:   "all failed",
+			status: http.StatusBadRequest,
+			body: `{
+				"issues": [],
+				"errors": [
+					{"failedElementNumber": 0, "elementErrors": {"errorMessages": ["no"]}},
+					{"failedElementNumber": 1, "elementErrors": {"errorMessages": ["no"]}},
+					{"failedElementNumber": 2, "elementErrors": {"errorMessages": ["no"]}}
+				]
+			}`,
+			err: `failed to create 3 issue(s): "First": no, "Second": no, "Third": no`,
+		},
+		{
+			name:   "unreadable 400",
+			status: http.StatusBadRequest,
+			body:   `<html>Bad Request</html>`,
+			err:    "Bad Request",
+		},
+		{
+			name:   "server error",
+			status: http.StatusInternalServerError,
+			body:   `{}`,
+			err:    "Internal Server Error",
+		},
+		{
+			name:        "no bulk API",
+			status:      http.StatusNotFound,
+			body:        `{}`,
+			unsupported: true,
+		},
+	}
+	for _, test := range tests {
+		chunk := make([]pendingIssue, 0, 3)
+		for i, summary := range []string{"First", "Second", "Third"} {
+			chunk = append(chunk, pendingIssue{
+				index:  i,
+				ticket: Ticket{Project: "PROJ"},
+				issue:  jira.Issue{Fields: &jira.IssueFields{Summary: summary}},
+			})
+		}
+		run := &Run{ID: test.name, dir: dir}
+		client := &jiraClient{jiraRequester: fakeBulkJIRA{test.status, test.body}}
+
+		err := bulkCreateIssues(client, chunk, run)
+		switch {
+		case test.unsupported:
+			if err != errBulkUnsupported {
+				t.Errorf("%s: got error %v, want errBulkUnsupported", test.name, err)
+			}
+		case test.err == "":
+			if err != nil {
+				t.Errorf("%s: %v", test.name, err)
+			}
+		case err == nil || err.Error() != test.err:
+			t.Errorf("%s: got error %v, want %q", test.name, err, test.err)
+		}
+
+		created := make([]string, 0, len(run.Created))
+		for _, issue := range run.Created {
+			created = append(created, issue.Key+" "+issue.Summary)
+		}
+		if strings.Join(created, ", ") != strings.Join(test.created, ", ") {
+			t.Errorf("%s: recorded %v, want %v", test.name, created, test.created)
+		}
+	}
+}
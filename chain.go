@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	chainLinks = createCmd.Flag(
+		"chain",
+		"Link each created issue as blocked by the one created before it.",
+	).Bool()
+)
+
+// prevKeyMarker is what .PrevKey renders as until the previous issue has
+// been created and its key is known.
+const prevKeyMarker = "<<prev-key>>"
+
+// usesPrevKey reports whether the issue's rendered text refers to the
+// previous issue's key.
+func usesPrevKey(issue Issue) bool {
+	return strings.Contains(issue.Summary, prevKeyMarker) ||
+		strings.Contains(issue.Description, prevKeyMarker) ||
+		strings.Contains(issue.Comment, prevKeyMarker)
+}
+
+// fillPrevKey replaces .PrevKey in the issue's rendered text with key.
+func fillPrevKey(issue *Issue, key string) {
+	issue.Summary = strings.Replace(issue.Summary, prevKeyMarker, key, -1)
+	issue.Description = strings.Replace(issue.Description, prevKeyMarker, key, -1)
+	issue.Comment = strings.Replace(issue.Comment, prevKeyMarker, key, -1)
+}
+
+// lastCreatedKey is the key of the issue created most recently in the run,
+// leaving out epics created from --hierarchy.
+func lastCreatedKey(run *Run) string {
+	for i := len(run.Created) - 1; i >= 0; i-- {
+		if run.Created[i].Ticket != hierarchyTicket {
+			return run.Created[i].Key
+		}
+	}
+	return ""
+}
+
+// createChained creates a batch of issues, splitting it before each issue
+// which uses .PrevKey so the key it refers to exists by the time it's
+// filled in.
+func createChained(tracker Tracker, batch issueBatch, run *Run) error {
+	issues := batch.issues
+	for len(issues) > 0 {
+		n := 1
+		for n < len(issues) && !usesPrevKey(issues[n]) {
+			n++
+		}
+		if usesPrevKey(issues[0]) {
+			fillPrevKey(&issues[0], lastCreatedKey(run))
+		}
+		if err := tracker.CreateIssues(batch.epic, issues[:n], run); err != nil {
+			return err
+		}
+		issues = issues[n:]
+	}
+	return nil
+}
+
+// linkChain links each of the run's issues as blocked by the one before it
+// in the tickets file.
+func linkChain(tracker Tracker, run *Run) error {
+	keys := createdInFileOrder(run)
+	for i := 1; i < len(keys); i++ {
+		if err := tracker.LinkIssues(keys[i-1], keys[i], linkBlocks); err != nil {
+			return err
+		}
+		fmt.Printf("Linked %s as blocked by %s\n", keys[i], keys[i-1])
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// describer is implemented by trackers which can read and rewrite an epic's
+// description.
+type describer interface {
+	GetDescription(key string) (string, error)
+	SetDescription(key string, description string) error
+}
+
+// checklistMarkers returns the lines between which the checklist is kept. In
+// JIRA these are anchors, and elsewhere HTML comments, so they don't show up
+// in the rendered description.
+func checklistMarkers() (string, string) {
+	if *backend == "jira" {
+		return "{anchor:epic-creator-begin}", "{anchor:epic-creator-end}"
+	}
+	return "<!-- epic-creator:begin -->", "<!-- epic-creator:end -->"
+}
+
+func checklistItem(issue CreatedIssue) string {
+	if *backend == "jira" {
+		return fmt.Sprintf("* %s %s", issue.Key, issue.Summary)
+	}
+	return fmt.Sprintf("- [ ] %s %s", issue.Key, issue.Summary)
+}
+
+// checklistItemMarks are what an item starts with before its issue's key:
+// list bullets, and GitHub and GitLab task boxes.
+var checklistItemMarks = map[string]bool{
+	"*": true, "-": true, "[": true, "]": true, "[x]": true, "[X]": true,
+}
+
+// checklistItemKey returns the key an item of the checklist starts with,
+// after its bullet and task box.
+func checklistItemKey(item string) string {
+	for _, word := range strings.Fields(item) {
+		if !checklistItemMarks[word] {
+			return word
+		}
+	}
+	return ""
+}
+
+// updateChecklist adds an item for each created issue to the checklist in
+// description, adding the checklist to the end if there isn't one yet.
+// Existing items, including any edits made to them, are kept, and issues
+// which already have an item are skipped, so re-running is idempotent.
+func updateChecklist(description string, created []CreatedIssue) string {
+	begin, end := checklistMarkers()
+
+	before, after := description, ""
+	items := make([]string, 0)
+	if i := strings.Index(description, begin); i >= 0 {
+		if j := strings.Index(description[i:], end); j >= 0 {
+			before = description[:i]
+			after = description[i+j+len(end):]
+			section := strings.Trim(description[i+len(begin):i+j], "\n")
+			if section != "" {
+				items = strings.Split(section, "\n")
+			}
+		}
+	}
+
+	for _, issue := range created {
+		listed := false
+		for _, item := range items {
+			if checklistItemKey(item) == issue.Key {
+				listed = true
+				break
+			}
+		}
+		if !listed {
+			items = append(items, checklistItem(issue))
+		}
+	}
+
+	if after == "" {
+		before = strings.TrimRight(before, "\n") + "\n\n"
+	}
+	return before + begin + "\n" + strings.Join(items, "\n") + "\n" + end + after
+}
+
+// updateEpicChecklist maintains the checklist of created issues in the
+// epic's description.
+func updateEpicChecklist(tracker Tracker, epic *Epic, created []CreatedIssue) error {
+	d, ok := tracker.(describer)
+	if !ok {
+		return fmt.Errorf("backend %s does not support updating the epic's description", *backend)
+	}
+
+	description, err := d.GetDescription(epic.Key)
+	if err != nil {
+		return err
+	}
+	updated := updateChecklist(description, created)
+	if updated == description {
+		return nil
+	}
+	if err := d.SetDescription(epic.Key, updated); err != nil {
+		return err
+	}
+	fmt.Printf("Updated checklist in %s.\n", epic.Key)
+	return nil
+}
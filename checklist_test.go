@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChecklistItemKey(t *testing.T) {
+	tests := []struct {
+		item string
+		key  string
+	}{
+		{"* PROJ-1 Write the schema", "PROJ-1"},
+		{"- [ ] PROJ-1 Write the schema", "PROJ-1"},
+		{"- [x] PROJ-1 Write the schema", "PROJ-1"},
+		{"* XPROJ-1 Write the schema", "XPROJ-1"},
+		{"* ABC-PROJ-1 Write the schema", "ABC-PROJ-1"},
+		{"- [ ] owner/repo#12 Write the schema", "owner/repo#12"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if key := checklistItemKey(test.item); key != test.key {
+			t.Errorf("%q: got key %q, want %q", test.item, key, test.key)
+		}
+	}
+}
+
+func TestUpdateChecklistMatchesWholeKeys(t *testing.T) {
+	*backend = "jira"
+	begin, end := checklistMarkers()
+	description := "Intro\n\n" + begin + "\n* XPROJ-1 Someone else's\n" + end
+	updated := updateChecklist(description, []CreatedIssue{{Key: "PROJ-1", Summary: "Ours"}})
+	if !strings.Contains(updated, "* PROJ-1 Ours") {
+		t.Errorf("PROJ-1 wasn't added, as XPROJ-1 was taken for it:\n%s", updated)
+	}
+	if again := updateChecklist(updated, []CreatedIssue{{Key: "PROJ-1", Summary: "Ours"}}); again != updated {
+		t.Errorf("PROJ-1 was added twice:\n%s", again)
+	}
+}
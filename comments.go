@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// commenter is implemented by trackers which can comment on issues.
+type commenter interface {
+	AddComment(key string, body string) error
+}
+
+// watcherAdder is implemented by trackers which can subscribe users to
+// issues.
+type watcherAdder interface {
+	AddWatcher(key string, user string) error
+}
+
+// parseComment parses a ticket's comment template, with the same functions
+// and delimiters as its summary and description.
+func parseComment(text string) (*template.Template, error) {
+	return newTemplate("comment").Parse(text)
+}
+
+// renderComment executes the ticket's comment template, if it has one.
+func renderComment(ctx ticketContext) (string, error) {
+	if ctx.Comment == "" {
+		return "", nil
+	}
+
+	tmpl, err := parseComment(ctx.Comment)
+	if err != nil {
+		return "", err
+	}
+
+	buf := bytes.NewBufferString("")
+	err = tmpl.Execute(buf, ctx)
+	return buf.String(), err
+}
+
+func addWatchers(tracker Tracker, run *Run, issue Issue, created CreatedIssue) error {
+	if len(issue.Ticket.Watchers) == 0 {
+		return nil
+	}
+
+	w, ok := tracker.(watcherAdder)
+	if !ok {
+		return fmt.Errorf("backend %s does not support watchers", *backend)
+	}
+
+	for _, user := range issue.Ticket.Watchers {
+		if err := w.AddWatcher(created.Key, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func postComment(tracker Tracker, run *Run, issue Issue, created CreatedIssue) error {
+	if issue.Comment == "" {
+		return nil
+	}
+
+	c, ok := tracker.(commenter)
+	if !ok {
+		return fmt.Errorf("backend %s does not support comments", *backend)
+	}
+	return c.AddComment(created.Key, issue.Comment)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	completionCmd = kingpin.Command(
+		"completion",
+		"Print a shell completion script, e.g. source <(epic-creator completion bash).",
+	)
+	completionShell = completionCmd.Arg("shell", "Shell to complete for.").Required().Enum("bash", "zsh", "fish")
+)
+
+// completionCommand is a command, with its flags and subcommands, as
+// completion scripts need it.
+type completionCommand struct {
+	// Path is the command's words, e.g. "bulk transition", and empty for
+	// the top level.
+	Path     string
+	Help     string
+	Flags    []*kingpin.FlagModel
+	Commands []*completionCommand
+}
+
+// completionCommands walks the command line, leaving out what's hidden.
+func completionCommands() *completionCommand {
+	model := kingpin.CommandLine.Model()
+	root := &completionCommand{Flags: visibleFlags(model.FlagGroupModel)}
+	root.Commands = completionSubcommands(model.CmdGroupModel)
+	return root
+}
+
+func completionSubcommands(group *kingpin.CmdGroupModel) []*completionCommand {
+	commands := make([]*completionCommand, 0)
+	if group == nil {
+		return commands
+	}
+	for _, cmd := range group.Commands {
+		if cmd.Hidden {
+			continue
+		}
+		commands = append(commands, &completionCommand{
+			Path:     cmd.FullCommand,
+			Help:     cmd.Help,
+			Flags:    visibleFlags(cmd.FlagGroupModel),
+			Commands: completionSubcommands(cmd.CmdGroupModel),
+		})
+	}
+	return commands
+}
+
+func visibleFlags(group *kingpin.FlagGroupModel) []*kingpin.FlagModel {
+	flags := make([]*kingpin.FlagModel, 0)
+	if group == nil {
+		return flags
+	}
+	for _, flag := range group.Flags {
+		if !flag.Hidden {
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
+
+// name is the last word of the command's path.
+func (c *completionCommand) name() string {
+	return c.Path[strings.LastIndex(c.Path, " ")+1:]
+}
+
+// all returns the command and every command below it.
+func (c *completionCommand) all() []*completionCommand {
+	all := []*completionCommand{c}
+	for _, sub := range c.Commands {
+		all = append(all, sub.all()...)
+	}
+	return all
+}
+
+// words are what can follow the command: its subcommands and flags.
+func (c *completionCommand) words(globals []*kingpin.FlagModel) []string {
+	words := make([]string, 0, len(c.Commands)+len(c.Flags)+len(globals))
+	for _, sub := range c.Commands {
+		words = append(words, sub.name())
+	}
+	for _, flag := range append(append([]*kingpin.FlagModel{}, c.Flags...), globals...) {
+		words = append(words, "--"+flag.Name)
+	}
+	sort.Strings(words)
+	return words
+}
+
+// bashCompletion completes the subcommands and flags of the command typed
+// so far, and file names otherwise. zsh runs it through bashcompinit.
+func bashCompletion(root *completionCommand) string {
+	buf := bytes.NewBufferString("")
+	fmt.Fprintln(buf, "_epic_creator() {")
+	fmt.Fprintln(buf, `    local cur="${COMP_WORDS[COMP_CWORD]}" cmd="" word opts`)
+	fmt.Fprintln(buf, `    for word in "${COMP_WORDS[@]:1:COMP_CWORD-1}"; do`)
+	fmt.Fprintln(buf, `        case "$cmd|$word" in`)
+	for _, cmd := range root.all()[1:] {
+		parent := strings.TrimSuffix(strings.TrimSuffix(cmd.Path, cmd.name()), " ")
+		fmt.Fprintf(buf, "            %q) cmd=%q ;;\n", parent+"|"+cmd.name(), cmd.Path)
+	}
+	fmt.Fprintln(buf, "        esac")
+	fmt.Fprintln(buf, "    done")
+	fmt.Fprintln(buf, `    case "$cmd" in`)
+	for _, cmd := range root.all() {
+		globals := root.Flags
+		if cmd == root {
+			globals = nil
+		}
+		fmt.Fprintf(buf, "        %q) opts=%q ;;\n", cmd.Path, strings.Join(cmd.words(globals), " "))
+	}
+	fmt.Fprintln(buf, "    esac")
+	fmt.Fprintln(buf, `    COMPREPLY=($(compgen -W "$opts" -- "$cur"))`)
+	fmt.Fprintln(buf, `    if [ ${#COMPREPLY[@]} -eq 0 ]; then`)
+	fmt.Fprintln(buf, `        COMPREPLY=($(compgen -f -- "$cur"))`)
+	fmt.Fprintln(buf, "    fi")
+	fmt.Fprintln(buf, "}")
+	fmt.Fprintln(buf, "complete -o filenames -F _epic_creator epic-creator")
+	return buf.String()
+}
+
+// fishQuote quotes s for fish.
+func fishQuote(s string) string {
+	return "'" + strings.Replace(strings.Replace(s, `\`, `\\`, -1), "'", `\'`, -1) + "'"
+}
+
+// fishCompletion completes each command's subcommands and flags once it's
+// been typed.
+func fishCompletion(root *completionCommand) string {
+	buf := bytes.NewBufferString("")
+	fmt.Fprintln(buf, "complete -c epic-creator -f")
+	for _, flag := range root.Flags {
+		fmt.Fprintf(buf, "complete -c epic-creator -l %s -d %s\n", flag.Name, fishQuote(flag.Help))
+	}
+	for _, cmd := range root.all() {
+		condition := "__fish_use_subcommand"
+		if cmd != root {
+			condition = "__fish_seen_subcommand_from " + cmd.name()
+		}
+		for _, sub := range cmd.Commands {
+			fmt.Fprintf(buf, "complete -c epic-creator -n %s -a %s -d %s\n", fishQuote(condition), sub.name(), fishQuote(sub.Help))
+		}
+		if cmd == root {
+			continue
+		}
+		for _, flag := range cmd.Flags {
+			fmt.Fprintf(buf, "complete -c epic-creator -n %s -l %s -d %s\n", fishQuote(condition), flag.Name, fishQuote(flag.Help))
+		}
+	}
+	return buf.String()
+}
+
+func runCompletion() {
+	root := completionCommands()
+	switch *completionShell {
+	case "bash":
+		fmt.Fprint(os.Stdout, bashCompletion(root))
+	case "zsh":
+		fmt.Fprintln(os.Stdout, "autoload -U +X bashcompinit && bashcompinit")
+		fmt.Fprint(os.Stdout, bashCompletion(root))
+	case "fish":
+		fmt.Fprint(os.Stdout, fishCompletion(root))
+	}
+}
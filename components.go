@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+import (
+	jira "gopkg.in/andygrunwald/go-jira.v1"
+)
+
+var (
+	createMissingComponents = createCmd.Flag(
+		"create-missing-components",
+		"Create components tickets name which don't exist in their JIRA project, instead of failing.",
+	).Bool()
+	componentLead = createCmd.Flag(
+		"component-lead",
+		"User to make the lead of components created by --create-missing-components.",
+	).String()
+)
+
+// jiraComponent is the body with which to create a component.
+type jiraComponent struct {
+	Name          string `json:"name"`
+	Project       string `json:"project"`
+	LeadUserName  string `json:"leadUserName,omitempty"`
+	LeadAccountID string `json:"leadAccountId,omitempty"`
+}
+
+// ensureComponents checks that the project has each of the ticket's
+// components, creating those it doesn't with --create-missing-components.
+// Created components are added to the project, so each is only created once.
+func ensureComponents(client *jiraClient, project *jira.Project, ticket Ticket) error {
+	for _, name := range ticket.Components {
+		found := false
+		for _, component := range project.Components {
+			if strings.EqualFold(component.Name, name) {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		if !*createMissingComponents {
+			return fmt.Errorf(
+				"component %q doesn't exist in %s; pass --create-missing-components to create it",
+				name,
+				project.Key,
+			)
+		}
+
+		component, err := createComponent(client, project.Key, name)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created component %s in %s.\n", component.Name, project.Key)
+		project.Components = append(project.Components, *component)
+	}
+	return nil
+}
+
+// hasComponents reports whether the project has each of the ticket's
+// components.
+func hasComponents(project *jira.Project, ticket Ticket) bool {
+	for _, name := range ticket.Components {
+		found := false
+		for _, component := range project.Components {
+			if strings.EqualFold(component.Name, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func createComponent(client *jiraClient, project string, name string) (*jira.ProjectComponent, error) {
+	body := jiraComponent{Name: name, Project: project}
+	if *componentLead != "" {
+		if jiraCloud {
+			body.LeadAccountID = *componentLead
+		} else {
+			body.LeadUserName = *componentLead
+		}
+	}
+
+	req, err := client.NewRequest("POST", "rest/api/2/component", body)
+	if err != nil {
+		return nil, err
+	}
+
+	component := new(jira.ProjectComponent)
+	resp, err := client.Do(req, component)
+	if err != nil {
+		return nil, jiraAPIRequestErrorHandler(resp, err)
+	}
+	return component, nil
+}
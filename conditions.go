@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+)
+
+var (
+	setParams = createCmd.Flag(
+		"set",
+		"Set a param on every ticket, overriding the tickets file, e.g. --set env=prod. May be repeated.",
+	).StringMap()
+)
+
+// falseConditions are the results of a when template which skip a ticket.
+var falseConditions = map[string]bool{
+	"":      true,
+	"false": true,
+	"0":     true,
+	"no":    true,
+	// A missing param renders as <no value>, which shouldn't enable the
+	// ticket.
+	"<no value>": true,
+}
+
+// ticketEnabled evaluates the ticket's when template, which has the same
+// context as the summary and description. The ticket is skipped if the
+// template renders empty, "false", "0", "no" or a missing param.
+func ticketEnabled(ctx ticketContext) (bool, error) {
+	if ctx.When == "" {
+		return true, nil
+	}
+
+	tmpl, err := newTemplate("when").Parse(ctx.When)
+	if err != nil {
+		return false, err
+	}
+
+	buf := bytes.NewBufferString("")
+	if err := tmpl.Execute(buf, ctx); err != nil {
+		return false, err
+	}
+	return !falseConditions[strings.ToLower(strings.TrimSpace(buf.String()))], nil
+}
+
+// applyParamOverrides sets the params given with --set on the ticket.
+func applyParamOverrides(ticket *Ticket) {
+	for k, v := range *setParams {
+		ticket.Params[k] = v
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	htmltemplate "html/template"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"text/template"
+)
+
+var (
+	confluenceSpace = createCmd.Flag(
+		"confluence-space",
+		"Key of a Confluence space to publish a page of each epic's created issues to, once they're created.",
+	).String()
+	confluenceURL = createCmd.Flag(
+		"confluence-url",
+		"Confluence instance URL. Defaults to <jira-url>/wiki on Jira Cloud.",
+	).URL()
+	confluenceParent = createCmd.Flag(
+		"confluence-parent",
+		"ID of the page to publish pages under.",
+	).String()
+	confluenceTitle = createCmd.Flag(
+		"confluence-title",
+		"Template for the title of the page. A page with the title already is updated rather than created.",
+	).Default("{{ .Epic.Key }} breakdown").String()
+	confluenceTemplatePath = createCmd.Flag(
+		"confluence-template",
+		"Path to template, in Confluence storage format, to use for the page instead of the default table.",
+	).ExistingFile()
+)
+
+// defaultConfluenceTemplate renders the created issues as a table, in
+// Confluence's storage format.
+const defaultConfluenceTemplate = `<p>Issues created in <a href="{{ .Epic.URL }}">{{ .Epic.Key }}</a> by epic-creator in run {{ .Run.ID }}.</p>
+<table>
+<tr><th>Key</th><th>Summary</th><th>Assignee</th></tr>
+{{ range .Issues }}<tr><td>{{ .Key }}</td><td>{{ .Summary }}</td><td>{{ .Assignee }}</td></tr>
+{{ end }}</table>`
+
+type confluenceBody struct {
+	Storage struct {
+		Value          string `json:"value"`
+		Representation string `json:"representation"`
+	} `json:"storage"`
+}
+
+type confluencePage struct {
+	ID        string             `json:"id,omitempty"`
+	Type      string             `json:"type"`
+	Title     string             `json:"title"`
+	Space     *confluenceSpaceID `json:"space,omitempty"`
+	Ancestors []confluencePageID `json:"ancestors,omitempty"`
+	Version   *struct {
+		Number int `json:"number"`
+	} `json:"version,omitempty"`
+	Body  *confluenceBody `json:"body,omitempty"`
+	Links struct {
+		Base  string `json:"base"`
+		WebUI string `json:"webui"`
+	} `json:"_links,omitempty"`
+}
+
+type confluenceSpaceID struct {
+	Key string `json:"key"`
+}
+
+type confluencePageID struct {
+	ID string `json:"id"`
+}
+
+func newConfluenceClient() (*restClient, error) {
+	base := ""
+	if *confluenceURL != nil {
+		base = (*confluenceURL).String()
+	} else if jiraCloud && *jiraURL != nil {
+		base = (*jiraURL).String() + "/wiki"
+	} else {
+		return nil, fmt.Errorf("--confluence-space needs --confluence-url")
+	}
+
+	creds, err := getCreds(*authFilePath)
+	if err != nil {
+		return nil, err
+	}
+	header := make(http.Header)
+	token := base64.StdEncoding.EncodeToString([]byte(creds.User + ":" + creds.Password))
+	header.Set("Authorization", "Basic "+token)
+	return newRESTClient(base, header), nil
+}
+
+// renderConfluencePage renders the page's title and storage format body.
+func renderConfluencePage(ctx epicCommentContext) (string, string, error) {
+	title, err := template.New("confluence-title").Funcs(dateFuncs).Parse(*confluenceTitle)
+	if err != nil {
+		return "", "", err
+	}
+	var body *htmltemplate.Template
+	if *confluenceTemplatePath == "" {
+		body, err = htmltemplate.New("confluence").Funcs(htmltemplate.FuncMap(dateFuncs)).Parse(defaultConfluenceTemplate)
+	} else {
+		body, err = htmltemplate.New(filepath.Base(*confluenceTemplatePath)).Funcs(htmltemplate.FuncMap(dateFuncs)).ParseFiles(*confluenceTemplatePath)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	titleBuf := bytes.NewBufferString("")
+	if err := title.Execute(titleBuf, ctx); err != nil {
+		return "", "", err
+	}
+	bodyBuf := bytes.NewBufferString("")
+	if err := body.Execute(bodyBuf, ctx); err != nil {
+		return "", "", err
+	}
+	return titleBuf.String(), bodyBuf.String(), nil
+}
+
+// publishConfluencePage publishes a page listing created, the issues the run
+// created in the epic, updating the page of the same title if there is one.
+func publishConfluencePage(epic *Epic, issues []Issue, run *Run, created []CreatedIssue) error {
+	client, err := newConfluenceClient()
+	if err != nil {
+		return err
+	}
+	title, body, err := renderConfluencePage(epicCommentContext{
+		Epic:   epic,
+		Run:    run,
+		Issues: epicCommentRows(issues, created),
+	})
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("spaceKey", *confluenceSpace)
+	query.Set("title", title)
+	query.Set("expand", "version")
+	var existing struct {
+		Results []confluencePage `json:"results"`
+	}
+	if err := client.do("GET", "/rest/api/content?"+query.Encode(), nil, &existing); err != nil {
+		return err
+	}
+
+	page := confluencePage{
+		Type:  "page",
+		Title: title,
+		Space: &confluenceSpaceID{Key: *confluenceSpace},
+		Body:  &confluenceBody{},
+	}
+	page.Body.Storage.Value = body
+	page.Body.Storage.Representation = "storage"
+	if *confluenceParent != "" {
+		page.Ancestors = []confluencePageID{{ID: *confluenceParent}}
+	}
+
+	var published confluencePage
+	if len(existing.Results) > 0 {
+		current := existing.Results[0]
+		if current.Version == nil {
+			return fmt.Errorf("page %s has no version to update", current.ID)
+		}
+		page.Version = current.Version
+		page.Version.Number++
+		err = client.do("PUT", "/rest/api/content/"+current.ID, &page, &published)
+	} else {
+		err = client.do("POST", "/rest/api/content", &page, &published)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Published %s: %s%s\n", title, published.Links.Base, published.Links.WebUI)
+	return nil
+}
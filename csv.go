@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	csvMappingPath = createCmd.Flag(
+		"csv-mapping",
+		"Path to a JSON mapping of CSV columns to ticket fields, for .csv tickets files.",
+	).ExistingFile()
+
+	importCSVCmd = kingpin.Command(
+		"import-csv",
+		"Convert a CSV file to a tickets file.",
+	)
+	importCSVMapping = importCSVCmd.Flag(
+		"mapping",
+		"Path to a JSON mapping of CSV columns to ticket fields.",
+	).ExistingFile()
+	importCSVOutput = importCSVCmd.Flag(
+		"output",
+		"Path to write the tickets file to, instead of stdout.",
+	).Short('o').String()
+	importCSVFile = importCSVCmd.Arg("csv", "CSV file to convert.").Required().ExistingFile()
+)
+
+// csvMapping says how the columns of a CSV file become tickets. Columns maps
+// a column's header to the ticket field it fills: "project", "assignee",
+// "params.<name>" and so on, or "-" to ignore the column. Columns which are
+// not mapped become params named after their header. Defaults are fields
+// every ticket starts with, in the same form as the tickets file.
+type csvMapping struct {
+	Columns  map[string]string      `json:"columns"`
+	Defaults map[string]interface{} `json:"defaults"`
+}
+
+// csvListFields are the ticket fields which take a comma-separated list:
+// every field of Ticket which is a list of strings.
+var csvListFields = ticketListFields()
+
+func ticketListFields() map[string]bool {
+	fields := make(map[string]bool)
+	t := reflect.TypeOf(Ticket{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type != reflect.TypeOf([]string(nil)) {
+			continue
+		}
+		if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+func loadCSVMapping(p string) (*csvMapping, error) {
+	mapping := &csvMapping{}
+	if p == "" {
+		return mapping, nil
+	}
+
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, mapping)
+	return mapping, err
+}
+
+// csvTickets reads a CSV file, whose first row is its header, into a ticket
+// per row.
+func csvTickets(p string, mappingPath string) ([]Ticket, error) {
+	mapping, err := loadCSVMapping(mappingPath)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", p, err)
+	}
+	return rowTickets(p, mapping, rows)
+}
+
+// rowTickets turns rows, the first of which is the header, into a ticket
+// per row. name is where the rows came from, for errors.
+func rowTickets(name string, mapping *csvMapping, rows [][]string) ([]Ticket, error) {
+	if len(rows) == 0 {
+		return []Ticket{}, nil
+	}
+
+	header := rows[0]
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	tickets := make([]Ticket, 0, len(rows)-1)
+	for n, row := range rows[1:] {
+		ticket, err := csvTicket(mapping, header, row)
+		if err != nil {
+			return nil, fmt.Errorf("%s: row %d: %v", name, n+2, err)
+		}
+		tickets = append(tickets, ticket)
+	}
+	return tickets, nil
+}
+
+func csvTicket(mapping *csvMapping, header []string, row []string) (Ticket, error) {
+	fields := make(map[string]interface{}, len(mapping.Defaults)+1)
+	for k, v := range mapping.Defaults {
+		fields[k] = v
+	}
+	params := make(map[string]interface{})
+	if defaults, ok := fields["params"].(map[string]interface{}); ok {
+		for k, v := range defaults {
+			params[k] = v
+		}
+	}
+	fields["params"] = params
+
+	for i, column := range header {
+		if i >= len(row) {
+			break
+		}
+		value := strings.TrimSpace(row[i])
+
+		field, ok := mapping.Columns[column]
+		if !ok {
+			field = "params." + column
+		}
+		switch {
+		case field == "-":
+		case strings.HasPrefix(field, "params."):
+			params[strings.TrimPrefix(field, "params.")] = value
+		case value == "":
+		case field == "story_points":
+			points, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return Ticket{}, fmt.Errorf("%s: %v", column, err)
+			}
+			fields[field] = points
+		case csvListFields[field]:
+			items := strings.Split(value, ",")
+			for j := range items {
+				items[j] = strings.TrimSpace(items[j])
+			}
+			fields[field] = items
+		default:
+			fields[field] = value
+		}
+	}
+
+	// Round trip through JSON, so fields are read exactly as they would
+	// be from a tickets file.
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return Ticket{}, err
+	}
+	var ticket Ticket
+	err = json.Unmarshal(data, &ticket)
+	return ticket, err
+}
+
+func runImportCSV() {
+	tickets, err := csvTickets(*importCSVFile, *importCSVMapping)
+	if err != nil {
+		panic(err)
+	}
+
+	data, err := json.MarshalIndent(tickets, "", "    ")
+	if err != nil {
+		panic(err)
+	}
+	data = append(data, '\n')
+
+	if *importCSVOutput == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := ioutil.WriteFile(*importCSVOutput, data, 0644); err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d ticket(s) to %s\n", len(tickets), *importCSVOutput)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCSVTicketLists(t *testing.T) {
+	header := []string{"labels", "components", "tags", "depends_on", "watchers"}
+	row := []string{"a, b", "API", "smoke", "schema,api", "alice"}
+	mapping := &csvMapping{Columns: map[string]string{
+		"labels":     "labels",
+		"components": "components",
+		"tags":       "tags",
+		"depends_on": "depends_on",
+		"watchers":   "watchers",
+	}}
+	ticket, err := csvTicket(mapping, header, row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, got := range map[string][]string{
+		"labels":     ticket.Labels,
+		"components": ticket.Components,
+		"tags":       ticket.Tags,
+		"depends_on": ticket.DependsOn,
+		"watchers":   ticket.Watchers,
+	} {
+		if len(got) == 0 {
+			t.Errorf("%s wasn't set", name)
+		}
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(ticket.Labels, want) {
+		t.Errorf("labels = %v, want %v", ticket.Labels, want)
+	}
+	if want := []string{"schema", "api"}; !reflect.DeepEqual(ticket.DependsOn, want) {
+		t.Errorf("depends_on = %v, want %v", ticket.DependsOn, want)
+	}
+}
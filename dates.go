@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// dateLayout is the format JIRA, GitLab and Linear all take dates in.
+const dateLayout = "2006-01-02"
+
+// parseInterval parses a scheduling interval. Besides anything
+// time.ParseDuration accepts, a number of days ("3d") or weeks ("2w") may be
+// given.
+func parseInterval(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if strings.HasSuffix(s, suffix) {
+			n, err := strconv.Atoi(strings.TrimSuffix(s, suffix))
+			if err != nil {
+				return 0, fmt.Errorf("invalid interval %q", s)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+// scheduledDueDate returns the due date of the n'th ticket to be created. A
+// due date given on the ticket wins; otherwise, if --due-start is set, tickets
+// are due --due-interval apart starting from it.
+func scheduledDueDate(ticket Ticket, n int) (string, error) {
+	if ticket.DueDate != "" {
+		if _, err := time.Parse(dateLayout, ticket.DueDate); err != nil {
+			return "", fmt.Errorf("due_date must be YYYY-MM-DD: %v", err)
+		}
+		return ticket.DueDate, nil
+	}
+	if *dueStart == "" {
+		return "", nil
+	}
+
+	start, err := time.Parse(dateLayout, *dueStart)
+	if err != nil {
+		return "", fmt.Errorf("--due-start must be YYYY-MM-DD: %v", err)
+	}
+	interval, err := parseInterval(*dueInterval)
+	if err != nil {
+		return "", err
+	}
+	return start.Add(time.Duration(n) * interval).Format(dateLayout), nil
+}
+
+// dateFuncs are template functions for working with dates. Dates are passed
+// last, so they can be used in pipelines:
+//
+//	{{ .DueDate | parseDate | addDays -7 | formatDate "Jan 2" }}
+var dateFuncs = template.FuncMap{
+	"now": time.Now,
+	"parseDate": func(s string) (time.Time, error) {
+		return time.Parse(dateLayout, s)
+	},
+	"formatDate": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+	"addDays": func(n int, t time.Time) time.Time {
+		return t.AddDate(0, 0, n)
+	},
+	"addInterval": func(interval string, t time.Time) (time.Time, error) {
+		d, err := parseInterval(interval)
+		return t.Add(d), err
+	},
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	templateDelims = kingpin.Flag(
+		"template-delims",
+		"Left and right delimiters of ticket templates, separated by a comma, e.g. '[[,]]', for templates whose text has {{ }} in it.",
+	).String()
+)
+
+// delims returns the delimiters given with --template-delims, or empty
+// strings, which text/template takes to mean {{ and }}.
+func delims() (string, string) {
+	if *templateDelims == "" {
+		return "", ""
+	}
+	parts := strings.SplitN(*templateDelims, ",", 2)
+	return parts[0], parts[1]
+}
+
+// checkTemplateDelims checks --template-delims is a pair of delimiters.
+func checkTemplateDelims() error {
+	if *templateDelims == "" {
+		return nil
+	}
+	parts := strings.Split(*templateDelims, ",")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("--template-delims should be two delimiters separated by a comma, e.g. '[[,]]'")
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkDependencies checks that every ticket's depends_on names the id of
+// another ticket, and that no ticket depends on itself through others.
+func checkDependencies(tickets []Ticket) error {
+	byID := make(map[string]int)
+	for i, ticket := range tickets {
+		if ticket.ID == "" {
+			continue
+		}
+		if j, ok := byID[ticket.ID]; ok {
+			return fmt.Errorf("tickets %d and %d both have id %q", j, i, ticket.ID)
+		}
+		byID[ticket.ID] = i
+	}
+	for i, ticket := range tickets {
+		for _, id := range ticket.DependsOn {
+			if _, ok := byID[id]; !ok {
+				return fmt.Errorf("ticket %d depends on %q, which no ticket has as its id", i, id)
+			}
+		}
+	}
+
+	// Walk the graph depth first, keeping the path so far, so a cycle
+	// can be reported in full.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for path[start] != id {
+				start++
+			}
+			cycle := append(append([]string{}, path[start:]...), id)
+			return fmt.Errorf("tickets depend on each other in a cycle: %s", strings.Join(cycle, " -> "))
+		}
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range tickets[byID[id]].DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		return nil
+	}
+	for _, ticket := range tickets {
+		if ticket.ID != "" {
+			if err := visit(ticket.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// orderByDependencies orders the issues so each comes after those it
+// depends on, keeping them in file order otherwise. Dependencies which
+// weren't selected are ignored. The graph is known to have no cycles.
+func orderByDependencies(issues []Issue) []Issue {
+	pending := make(map[string]bool)
+	for _, issue := range issues {
+		if issue.Ticket.ID != "" {
+			pending[issue.Ticket.ID] = true
+		}
+	}
+
+	ordered := make([]Issue, 0, len(issues))
+	done := make([]bool, len(issues))
+	for len(ordered) < len(issues) {
+		for i, issue := range issues {
+			if done[i] {
+				continue
+			}
+			ready := true
+			for _, dep := range issue.Ticket.DependsOn {
+				if pending[dep] {
+					ready = false
+					break
+				}
+			}
+			if !ready {
+				continue
+			}
+			ordered = append(ordered, issue)
+			done[i] = true
+			delete(pending, issue.Ticket.ID)
+			// Start over, so earlier tickets waiting on this one
+			// go next.
+			break
+		}
+	}
+	return ordered
+}
+
+// linkDependencies links each of the run's issues as blocked by the issues
+// it depends on.
+func linkDependencies(tracker Tracker, issues []Issue, run *Run) error {
+	byIndex := indexIssues(issues)
+	keys := make(map[string]string)
+	for _, created := range run.Created {
+		if id := byIndex[created.Ticket].Ticket.ID; id != "" {
+			keys[id] = created.Key
+		}
+	}
+
+	for _, created := range run.Created {
+		for _, dep := range byIndex[created.Ticket].Ticket.DependsOn {
+			key, ok := keys[dep]
+			if !ok {
+				fmt.Printf("Not linking %s to %s, which wasn't created in this run.\n", created.Key, dep)
+				continue
+			}
+			if err := tracker.LinkIssues(key, created.Key, linkBlocks); err != nil {
+				return err
+			}
+			fmt.Printf("Linked %s as blocked by %s\n", created.Key, key)
+		}
+	}
+	return nil
+}
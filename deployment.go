@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	jiraDeployment = kingpin.Flag(
+		"jira-deployment",
+		"Kind of JIRA instance: cloud, server (including Data Center), or auto to detect it.",
+	).Default("auto").Enum("auto", "cloud", "server")
+)
+
+// jiraCloud is set once the JIRA instance is known to be Jira Cloud, which
+// differs from Server and Data Center in how epics, descriptions and users
+// are given.
+var jiraCloud bool
+
+type jiraServerInfo struct {
+	Version        string `json:"version"`
+	DeploymentType string `json:"deploymentType"`
+}
+
+// detectJIRADeployment sets jiraCloud from --jira-deployment, asking the
+// instance for its server info if it's auto.
+func detectJIRADeployment(client *jiraClient) error {
+	switch *jiraDeployment {
+	case "cloud":
+		jiraCloud = true
+		return nil
+	case "server":
+		return nil
+	}
+
+	req, err := client.NewRequest("GET", "rest/api/2/serverInfo", nil)
+	if err != nil {
+		return err
+	}
+	var info jiraServerInfo
+	resp, err := client.Do(req, &info)
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+
+	jiraCloud = info.DeploymentType == "Cloud"
+	if jiraCloud {
+		fmt.Println("Detected Jira Cloud.")
+	} else {
+		fmt.Printf("Detected JIRA %s %s.\n", info.DeploymentType, info.Version)
+	}
+	return nil
+}
+
+// useADF reports whether descriptions are submitted as ADF through the v3
+// API, as Jira Cloud expects.
+func useADF() bool {
+	return *jiraADF || jiraCloud
+}
+
+// jiraUser returns the value of a user field: an accountId on Cloud and a
+// username on Server and Data Center.
+func jiraUser(user string) map[string]string {
+	if jiraCloud {
+		return map[string]string{"accountId": user}
+	}
+	return map[string]string{"name": user}
+}
+
+// authHint explains how to fix a rejected login.
+func authHint() string {
+	if jiraCloud {
+		return "Jira Cloud doesn't accept account passwords: set password in the auth file to an API token (https://id.atlassian.com/manage-profile/security/api-tokens)."
+	}
+	return "Check the user and password in the auth file; the password may also be a personal access token."
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	ageCommand = kingpin.Flag(
+		"age",
+		"Command with which to decrypt .age auth files.",
+	).Default("age").String()
+	ageIdentity = kingpin.Flag(
+		"age-identity",
+		"Identity file to decrypt an .age auth file with. Without one, age asks for the passphrase.",
+	).Envar("EPIC_CREATOR_AGE_IDENTITY").String()
+	gpgCommand = kingpin.Flag(
+		"gpg",
+		"Command with which to decrypt .gpg and .asc auth files.",
+	).Default("gpg").String()
+)
+
+// decryptedAuthFiles holds the contents of the auth files decrypted so far,
+// by path, so the passphrase is only asked for once per run.
+var decryptedAuthFiles = make(map[string][]byte)
+
+// decryptCommand returns the command which decrypts an auth file to stdout,
+// or nil if it isn't encrypted.
+func decryptCommand(p string) *exec.Cmd {
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".age":
+		args := []string{"--decrypt"}
+		if *ageIdentity != "" {
+			args = append(args, "--identity", *ageIdentity)
+		}
+		return exec.Command(*ageCommand, append(args, p)...)
+	case ".gpg", ".asc":
+		return exec.Command(*gpgCommand, "--quiet", "--decrypt", p)
+	}
+	return nil
+}
+
+// readAuthFile reads the auth file, decrypting it with age or GPG if it's
+// encrypted. Either asks for a passphrase on the terminal if it needs one.
+func readAuthFile(p string) ([]byte, error) {
+	cmd := decryptCommand(p)
+	if cmd == nil {
+		return ioutil.ReadFile(p)
+	}
+	if data, ok := decryptedAuthFiles[p]; ok {
+		return data, nil
+	}
+	// A missing file is reported as such, rather than as the decrypt
+	// command failing, so netrc can be fallen back to.
+	if _, err := os.Stat(p); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("decrypting %s: %v", p, err)
+	}
+	decryptedAuthFiles[p] = out.Bytes()
+	return out.Bytes(), nil
+}
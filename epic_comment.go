@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// defaultEpicCommentTemplate renders the created issues as a JIRA wiki table.
+const defaultEpicCommentTemplate = `Created by epic-creator in run {{ .Run.ID }}:
+
+||Key||Summary||Assignee||
+{{ range .Issues }}|{{ .Key }}|{{ tableCell .Summary }}|{{ tableCell .Assignee }}|
+{{ end }}`
+
+// epicCommentRow is a created issue, as seen by the epic comment template.
+type epicCommentRow struct {
+	Key      string
+	Summary  string
+	Assignee string
+}
+
+// epicCommentContext is the context the epic comment template is executed
+// with.
+type epicCommentContext struct {
+	Epic   *Epic
+	Run    *Run
+	Issues []epicCommentRow
+}
+
+var epicCommentFuncs = template.FuncMap{
+	// tableCell escapes text for use in a wiki table cell.
+	"tableCell": func(s string) string {
+		return strings.Replace(s, "|", "\\|", -1)
+	},
+}
+
+// loadEpicCommentTemplate loads the epic comment template, built like the
+// ticket templates with newTemplate. The default is written with the
+// standard delimiters, whatever --template-delims says.
+func loadEpicCommentTemplate(templatePath string) (*template.Template, error) {
+	if templatePath == "" {
+		return newTemplate("epic-comment").Delims("", "").Funcs(epicCommentFuncs).Parse(defaultEpicCommentTemplate)
+	}
+	return newTemplate(filepath.Base(templatePath)).Funcs(epicCommentFuncs).ParseFiles(templatePath)
+}
+
+// epicCommentRows are the created issues, in the order they were created.
+func epicCommentRows(issues []Issue, created []CreatedIssue) []epicCommentRow {
+	rows := make([]epicCommentRow, 0, len(created))
+	byIndex := indexIssues(issues)
+	for _, c := range created {
+		rows = append(rows, epicCommentRow{
+			Key:      c.Key,
+			Summary:  c.Summary,
+			Assignee: byIndex[c.Ticket].Ticket.Assignee,
+		})
+	}
+	return rows
+}
+
+// commentOnEpic posts a comment on the epic listing created, the issues the
+// run created in it.
+func commentOnEpic(tracker Tracker, epic *Epic, issues []Issue, run *Run, created []CreatedIssue) error {
+	c, ok := tracker.(commenter)
+	if !ok {
+		return fmt.Errorf("backend %s does not support comments", *backend)
+	}
+
+	tmpl, err := loadEpicCommentTemplate(*epicCommentTemplatePath)
+	if err != nil {
+		return err
+	}
+
+	ctx := epicCommentContext{
+		Epic:   epic,
+		Run:    run,
+		Issues: epicCommentRows(issues, created),
+	}
+	buf := bytes.NewBufferString("")
+	if err := tmpl.Execute(buf, ctx); err != nil {
+		return err
+	}
+	if err := c.AddComment(epic.Key, buf.String()); err != nil {
+		return err
+	}
+	fmt.Printf("Commented on %s.\n", epic.Key)
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/trivago/tgo/tcontainer"
+)
+
+// epicColours is how many colours JIRA Software has for epics.
+const epicColours = 14
+
+// parseEpicColour takes an epic colour as its number, from 1 to 14, or as
+// a ghx-label-N or color_N key.
+func parseEpicColour(colour string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(colour, "ghx-label-"), "color_"))
+	if err != nil || n < 1 || n > epicColours {
+		return 0, fmt.Errorf("epic colour %q should be a number from 1 to %d", colour, epicColours)
+	}
+	return n, nil
+}
+
+// setEpicFields sets the node's colour, team and other fields on a new epic.
+// It returns the colour to set through the agile API once the epic is
+// created, on instances without an Epic Colour field.
+func (t *jiraTracker) setEpicFields(node hierarchyNode, unknowns tcontainer.MarshalMap) (int, error) {
+	colour := 0
+	if node.Colour != "" {
+		n, err := parseEpicColour(node.Colour)
+		if err != nil {
+			return 0, err
+		}
+		field, err := t.fieldID("Epic Colour")
+		if err == nil && field == "" {
+			field, err = t.fieldID("Epic Color")
+		}
+		if err != nil {
+			return 0, err
+		}
+		if field != "" {
+			unknowns[field] = fmt.Sprintf("ghx-label-%d", n)
+		} else {
+			colour = n
+		}
+	}
+
+	if node.Team != "" {
+		if err := t.setTeam(node.Team, unknowns); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := t.setFields(node.Fields, unknowns); err != nil {
+		return 0, err
+	}
+	return colour, nil
+}
+
+// setEpicColour sets the epic's colour through the agile API.
+func (t *jiraTracker) setEpicColour(key string, colour int) error {
+	body := map[string]interface{}{
+		"color": map[string]string{"key": fmt.Sprintf("color_%d", colour)},
+	}
+	req, err := t.client.NewRequest("POST", "rest/agile/1.0/epic/"+key, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Do(req, nil)
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+)
+
+var (
+	allowClosedEpic = createCmd.Flag(
+		"allow-closed-epic",
+		"Create issues in epics which are done or closed.",
+	).Bool()
+	sameProject = createCmd.Flag(
+		"same-project",
+		"Fail if a ticket's project isn't the project of its epic.",
+	).Bool()
+)
+
+// notEpicError is returned when the issue named as an epic is some other
+// type of issue.
+type notEpicError struct {
+	Key  string
+	Type string
+}
+
+func (e *notEpicError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("%s is not an epic", e.Key)
+	}
+	return fmt.Sprintf("%s is a %s, not an epic", e.Key, e.Type)
+}
+
+// closedEpicError is returned for epics whose work is finished, unless
+// --allow-closed-epic is given.
+type closedEpicError struct {
+	Key    string
+	Status string
+}
+
+func (e *closedEpicError) Error() string {
+	return fmt.Sprintf("%s is %s; pass --allow-closed-epic to create issues in it anyway", e.Key, e.Status)
+}
+
+// epicProjectError is returned with --same-project for tickets in a
+// different project to their epic.
+type epicProjectError struct {
+	Ticket        int
+	TicketProject string
+	Epic          string
+	EpicProject   string
+}
+
+func (e *epicProjectError) Error() string {
+	return fmt.Sprintf(
+		"ticket %d is in %s, but its epic %s is in %s",
+		e.Ticket,
+		e.TicketProject,
+		e.Epic,
+		e.EpicProject,
+	)
+}
+
+// checkEpic fails for epics which issues shouldn't be created in.
+func checkEpic(epic *Epic) error {
+	if epic.Closed && !*allowClosedEpic {
+		return &closedEpicError{Key: epic.Key, Status: epic.Status}
+	}
+	return nil
+}
+
+// checkTicketProject fails, with --same-project, if the ticket at index i
+// isn't in its epic's project.
+func checkTicketProject(i int, ticket Ticket, epic *Epic) error {
+	if !*sameProject || epic.Project == "" || ticket.Project == epic.Project {
+		return nil
+	}
+	return &epicProjectError{
+		Ticket:        i,
+		TicketProject: ticket.Project,
+		Epic:          epic.Key,
+		EpicProject:   epic.Project,
+	}
+}
+
+// epicGroup is the issues of a run which are created in the same epic.
+type epicGroup struct {
+	epic   *Epic
+	issues []Issue
+}
+
+// groupByEpic groups issues by their epic, with the groups in the order
+// their epics first appear.
+func groupByEpic(issues []Issue) []epicGroup {
+	groups := make([]epicGroup, 0)
+	byKey := make(map[string]int)
+	for _, issue := range issues {
+		i, ok := byKey[issue.Epic.Key]
+		if !ok {
+			i = len(groups)
+			byKey[issue.Epic.Key] = i
+			groups = append(groups, epicGroup{epic: issue.Epic})
+		}
+		groups[i].issues = append(groups[i].issues, issue)
+	}
+	return groups
+}
+
+// created returns the issues of the run which were created in the group.
+func (g epicGroup) created(run *Run) []CreatedIssue {
+	byIndex := indexIssues(g.issues)
+	created := make([]CreatedIssue, 0, len(g.issues))
+	for _, c := range run.Created {
+		if _, ok := byIndex[c.Ticket]; ok {
+			created = append(created, c)
+		}
+	}
+	return created
+}
+
+// epicResolver resolves the epics named by tickets, each only once. Tickets
+// which don't name an epic go in the default epic.
+type epicResolver struct {
+	tracker Tracker
+	dflt    *Epic
+	epics   map[string]*Epic
+}
+
+// newEpicResolver returns a resolver whose default is epic, which may be nil
+// until one is added.
+func newEpicResolver(tracker Tracker, epic *Epic) *epicResolver {
+	r := &epicResolver{
+		tracker: tracker,
+		epics:   make(map[string]*Epic),
+	}
+	if epic != nil {
+		r.Add(epic.Key, epic)
+	}
+	return r
+}
+
+// Add makes epic known by name. The first epic added is the default.
+func (r *epicResolver) Add(name string, epic *Epic) {
+	r.epics[name] = epic
+	if r.dflt == nil {
+		r.dflt = epic
+	}
+}
+
+// Default returns the epic for tickets which don't name one, or nil if
+// there is none yet.
+func (r *epicResolver) Default() *Epic {
+	return r.dflt
+}
+
+// ForTicket returns the epic the ticket is to be created in.
+func (r *epicResolver) ForTicket(ticket Ticket) (*Epic, error) {
+	if ticket.Epic != "" {
+		return r.Resolve(ticket.Epic)
+	}
+	if r.dflt == nil {
+		return nil, fmt.Errorf("no epic; name one on the command line or in the ticket")
+	}
+	return r.dflt, nil
+}
+
+// Resolve returns the epic named by key, failing if it is closed.
+func (r *epicResolver) Resolve(key string) (*Epic, error) {
+	if epic, ok := r.epics[key]; ok {
+		return epic, nil
+	}
+
+	epic, err := r.tracker.ResolveEpic(key)
+	if err != nil {
+		return nil, err
+	}
+	if epic == nil {
+		return nil, &notEpicError{Key: key}
+	}
+	if err := checkEpic(epic); err != nil {
+		return nil, err
+	}
+
+	// Keys may be given in any case, so an epic already resolved by its
+	// canonical key is reused.
+	if existing, ok := r.epics[epic.Key]; ok {
+		epic = existing
+	}
+	r.epics[key] = epic
+	r.epics[epic.Key] = epic
+	return epic, nil
+}
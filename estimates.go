@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// storyPointsSchemas are the custom field types JIRA Software uses for story
+// points, on classic and next-gen projects respectively.
+var storyPointsSchemas = []string{
+	"com.atlassian.jira.plugin.system.customfieldtypes:float",
+	"com.pyxis.greenhopper.jira:jsw-story-points",
+}
+
+// storyPointsNames are the names the story points field goes by.
+var storyPointsNames = []string{
+	"Story Points",
+	"Story point estimate",
+}
+
+type jiraField struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Custom bool   `json:"custom"`
+	Schema struct {
+		Type   string `json:"type"`
+		Custom string `json:"custom"`
+	} `json:"schema"`
+}
+
+func getFields(client *jiraClient) ([]jiraField, error) {
+	req, err := client.NewRequest("GET", "rest/api/2/field", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]jiraField, 0)
+	resp, err := client.Do(req, &fields)
+	if err != nil {
+		return nil, jiraAPIRequestErrorHandler(resp, err)
+	}
+	return fields, nil
+}
+
+// findStoryPointsField finds the ID of the custom field holding story
+// points, by its name and type.
+func findStoryPointsField(fields []jiraField) (string, error) {
+	for _, name := range storyPointsNames {
+		for _, field := range fields {
+			if !field.Custom || !strings.EqualFold(field.Name, name) {
+				continue
+			}
+			for _, schema := range storyPointsSchemas {
+				if field.Schema.Custom == schema {
+					return field.ID, nil
+				}
+			}
+		}
+	}
+	return "", fmt.Errorf("could not find a story points field; set one with --story-points-field")
+}
+
+// storyPointsField returns the story points field, detecting it on first
+// use unless --story-points-field is given.
+func (t *jiraTracker) storyPointsField() (string, error) {
+	if t.pointsField == "" {
+		t.pointsField = *storyPointsFieldID
+	}
+	if t.pointsField != "" {
+		return t.pointsField, nil
+	}
+
+	fields, err := t.allFields()
+	if err != nil {
+		return "", err
+	}
+	field, err := findStoryPointsField(fields)
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("Using %s for story points.\n", field)
+	t.pointsField = field
+	return field, nil
+}
+
+// printPointsRollUp prints the number of story points created in the epic.
+func printPointsRollUp(epic *Epic, issues []Issue, created []CreatedIssue) {
+	total := 0.0
+	pointed := 0
+	byIndex := indexIssues(issues)
+	for _, c := range created {
+		if points := byIndex[c.Ticket].Ticket.StoryPoints; points != 0 {
+			total += points
+			pointed++
+		}
+	}
+	if pointed == 0 {
+		return
+	}
+	fmt.Printf(
+		"Created %g story points in %s across %d of %d issue(s).\n",
+		total,
+		epic.Key,
+		pointed,
+		len(created),
+	)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	jsonnetCommand = createCmd.Flag(
+		"jsonnet",
+		"Command with which to evaluate .jsonnet tickets files.",
+	).Default("jsonnet").String()
+	cueCommand = createCmd.Flag(
+		"cue",
+		"Command with which to evaluate .cue tickets files.",
+	).Default("cue").String()
+)
+
+// evaluatedTicketFile returns the command which evaluates a Jsonnet or CUE
+// tickets file to JSON, or nil if the file is neither.
+func evaluatedTicketFile(p string) *exec.Cmd {
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".jsonnet":
+		// Library imports are resolved relative to the file.
+		return exec.Command(*jsonnetCommand, "-J", filepath.Dir(p), p)
+	case ".cue":
+		return exec.Command(*cueCommand, "export", "--out", "json", p)
+	}
+	return nil
+}
+
+// evaluateTicketFile runs a Jsonnet or CUE tickets file and returns the JSON
+// it evaluates to.
+func evaluateTicketFile(cmd *exec.Cmd, p string) (*bytes.Buffer, error) {
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("evaluating %s: %v", p, err)
+	}
+	return &out, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+)
+
+var (
+	fieldMappingPath = createCmd.Flag(
+		"field-mapping",
+		"Path to a JSON mapping of ticket params to the ticket fields they fill, e.g. {\"owner\": \"assignee\"}.",
+	).ExistingFile()
+	assigneeFromParam = createCmd.Flag(
+		"assignee-from-param",
+		"Param to take the assignee of tickets without one from.",
+	).String()
+)
+
+// loadFieldMapping reads --field-mapping, adding --assignee-from-param, and
+// checks that it maps onto ticket fields.
+func loadFieldMapping() (map[string]string, error) {
+	mapping := make(map[string]string)
+	if *fieldMappingPath != "" {
+		data, err := ioutil.ReadFile(*fieldMappingPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &mapping); err != nil {
+			return nil, fmt.Errorf("%s: %v", *fieldMappingPath, err)
+		}
+	}
+	if *assigneeFromParam != "" {
+		mapping[*assigneeFromParam] = "assignee"
+	}
+
+	for param, field := range mapping {
+		f, ok := ticketFields[field]
+		if !ok || field == "params" || field == "include" {
+			return nil, fmt.Errorf("param %q is mapped to %q, which isn't a ticket field", param, field)
+		}
+		if f.kind == kindObject || f.kind == kindMatrix || f.kind == kindLinks {
+			return nil, fmt.Errorf("param %q can't be mapped to %q", param, field)
+		}
+	}
+	return mapping, nil
+}
+
+// mappedValue converts a param to the kind of ticket field it's mapped to,
+// so that, say, a single component can be given as a string.
+func mappedValue(kind fieldKind, value interface{}) interface{} {
+	switch kind {
+	case kindStrings:
+		if s, ok := value.(string); ok {
+			return []interface{}{s}
+		}
+	case kindNumber:
+		if s, ok := value.(string); ok {
+			if n, err := strconv.ParseFloat(s, 64); err == nil {
+				return n
+			}
+		}
+	}
+	return value
+}
+
+// applyFieldMapping fills the fields of each ticket from the params mapped
+// to them. Fields given on the ticket win, and the params are kept, for the
+// templates.
+func applyFieldMapping(tickets []Ticket) ([]Ticket, error) {
+	mapping, err := loadFieldMapping()
+	if err != nil || len(mapping) == 0 {
+		return tickets, err
+	}
+
+	mapped := make([]Ticket, len(tickets))
+	for i, ticket := range tickets {
+		// Round trip through JSON, as csvTicket does, so fields are read
+		// exactly as they would be from a tickets file.
+		data, err := json.Marshal(ticket)
+		if err != nil {
+			return nil, err
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+
+		for param, field := range mapping {
+			value, ok := ticket.Params[param]
+			if current := fields[field]; !ok || (current != nil && current != "") {
+				continue
+			}
+			value = mappedValue(ticketFields[field].kind, value)
+			if err := ticketFields[field].check(value); err != nil {
+				return nil, fmt.Errorf("ticket %d: param %q, mapped to %q: %v", i, param, field, err)
+			}
+			fields[field] = value
+		}
+
+		data, err = json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &mapped[i]); err != nil {
+			return nil, fmt.Errorf("ticket %d: %v", i, err)
+		}
+	}
+	return mapped, nil
+}
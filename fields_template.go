@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+var (
+	fieldsTemplatePath = createCmd.Flag(
+		"fields-template",
+		"Path to a template rendering a JSON object of fields, by name or ID, to set on each issue. JIRA only.",
+	).ExistingFile()
+)
+
+// loadFieldsTemplate loads --fields-template, if given.
+func loadFieldsTemplate() (*template.Template, error) {
+	if *fieldsTemplatePath == "" {
+		return nil, nil
+	}
+	if *backend != "jira" {
+		return nil, fmt.Errorf("backend %s does not support --fields-template", *backend)
+	}
+	return loadTemplate(*fieldsTemplatePath)
+}
+
+// renderFields executes the fields template against the ticket, and parses
+// what it renders as a JSON object.
+func renderFields(tmpl *template.Template, ctx ticketContext) (map[string]interface{}, error) {
+	if tmpl == nil {
+		return nil, nil
+	}
+
+	buf := bytes.NewBufferString("")
+	if err := tmpl.Execute(buf, ctx); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(buf.Bytes(), &fields); err != nil {
+		return nil, fmt.Errorf("fields template didn't render a JSON object: %v", err)
+	}
+	return fields, nil
+}
+
+// resolveField finds the ID of a field given by its ID or its name.
+func (t *jiraTracker) resolveField(name string) (string, error) {
+	if strings.HasPrefix(name, "customfield_") {
+		return name, nil
+	}
+	fields, err := t.allFields()
+	if err != nil {
+		return "", err
+	}
+	for _, field := range fields {
+		if field.ID == name {
+			return name, nil
+		}
+	}
+	id, err := t.fieldID(name)
+	if err == nil && id == "" {
+		err = fmt.Errorf("there's no field named %q", name)
+	}
+	return id, err
+}
+
+// setFields sets fields, by name or ID, overriding any set already.
+func (t *jiraTracker) setFields(fields map[string]interface{}, unknowns map[string]interface{}) error {
+	for name, value := range fields {
+		id, err := t.resolveField(name)
+		if err != nil {
+			return err
+		}
+		unknowns[id] = value
+	}
+	return nil
+}
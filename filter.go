@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	onlyTickets = createCmd.Flag(
+		"only",
+		"Create only the tickets at these indexes in the tickets file, counting from 0, e.g. 0-4,7. May be repeated.",
+	).Strings()
+	skipTickets = createCmd.Flag(
+		"skip",
+		"Don't create the tickets at these indexes in the tickets file, e.g. 5-9. May be repeated.",
+	).Strings()
+	onlyTags = createCmd.Flag(
+		"tag",
+		"Create only tickets with one of these tags. May be repeated.",
+	).Strings()
+	skipTags = createCmd.Flag(
+		"skip-tag",
+		"Don't create tickets with any of these tags. May be repeated.",
+	).Strings()
+	limitTickets = createCmd.Flag(
+		"limit",
+		"Create at most this many tickets, e.g. to try a tickets file out against a sandbox.",
+	).Int()
+)
+
+// indexRange is an inclusive range of ticket indexes.
+type indexRange struct {
+	from, to int
+}
+
+// ticketFilter selects which tickets of the tickets file a run creates.
+type ticketFilter struct {
+	only     []indexRange
+	skip     []indexRange
+	tags     map[string]bool
+	skipTags map[string]bool
+	limit    int
+	selected int
+}
+
+// parseIndexRanges parses comma-separated indexes and ranges, like "0-4,7".
+func parseIndexRanges(values []string) ([]indexRange, error) {
+	ranges := make([]indexRange, 0)
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			bounds := strings.SplitN(part, "-", 2)
+			from, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid ticket index range %q", part)
+			}
+			to := from
+			if len(bounds) == 2 {
+				if to, err = strconv.Atoi(bounds[1]); err != nil || to < from {
+					return nil, fmt.Errorf("invalid ticket index range %q", part)
+				}
+			}
+			ranges = append(ranges, indexRange{from, to})
+		}
+	}
+	return ranges, nil
+}
+
+func tagSet(values []string) map[string]bool {
+	tags := make(map[string]bool)
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags[tag] = true
+			}
+		}
+	}
+	return tags
+}
+
+// newTicketFilter builds a ticketFilter from --only, --skip, --tag,
+// --skip-tag and --limit.
+func newTicketFilter() (*ticketFilter, error) {
+	only, err := parseIndexRanges(*onlyTickets)
+	if err != nil {
+		return nil, err
+	}
+	skip, err := parseIndexRanges(*skipTickets)
+	if err != nil {
+		return nil, err
+	}
+	if *limitTickets < 0 {
+		return nil, fmt.Errorf("--limit must not be negative")
+	}
+
+	return &ticketFilter{
+		only:     only,
+		skip:     skip,
+		tags:     tagSet(*onlyTags),
+		skipTags: tagSet(*skipTags),
+		limit:    *limitTickets,
+	}, nil
+}
+
+func inRanges(ranges []indexRange, i int) bool {
+	for _, r := range ranges {
+		if i >= r.from && i <= r.to {
+			return true
+		}
+	}
+	return false
+}
+
+// Selects reports whether the ticket at index i of the tickets file is to be
+// created. It must be called for tickets in order, as it counts them against
+// the limit.
+func (f *ticketFilter) Selects(i int, ticket Ticket) bool {
+	if len(f.only) > 0 && !inRanges(f.only, i) {
+		return false
+	}
+	if inRanges(f.skip, i) {
+		return false
+	}
+
+	tagged := len(f.tags) == 0
+	for _, tag := range ticket.Tags {
+		if f.skipTags[tag] {
+			return false
+		}
+		if f.tags[tag] {
+			tagged = true
+		}
+	}
+	if !tagged {
+		return false
+	}
+
+	if f.limit > 0 && f.selected >= f.limit {
+		return false
+	}
+	f.selected++
+	return true
+}
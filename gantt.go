@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	scheduleStart = createCmd.Flag(
+		"start-date",
+		"Schedule tickets from this date, as YYYY-MM-DD, by their estimates, dependencies and assignees, setting their start and due dates.",
+	).String()
+	capacity = createCmd.Flag(
+		"capacity",
+		"Hours a day an assignee works on the epic, for --start-date, e.g. --capacity alice=4. May be repeated.",
+	).StringMap()
+	defaultCapacity = createCmd.Flag(
+		"default-capacity",
+		"Hours a day assignees not given a --capacity work on the epic.",
+	).Default("8").Float64()
+)
+
+// JIRA's default time tracking settings: an 8 hour day and a 5 day week.
+const (
+	hoursPerDay = 8
+	daysPerWeek = 5
+)
+
+// parseEstimate parses a JIRA duration, such as "1w 2d 4h 30m", into a
+// number of hours.
+func parseEstimate(s string) (float64, error) {
+	units := map[string]float64{
+		"w": hoursPerDay * daysPerWeek,
+		"d": hoursPerDay,
+		"h": 1,
+		"m": 1.0 / 60,
+	}
+	hours := 0.0
+	for _, part := range strings.Fields(s) {
+		unit, ok := units[part[len(part)-1:]]
+		n, err := strconv.ParseFloat(part[:len(part)-1], 64)
+		if !ok || err != nil {
+			return 0, fmt.Errorf("estimate %q should be like 1w 2d 4h 30m", s)
+		}
+		hours += n * unit
+	}
+	return hours, nil
+}
+
+// addWorkingDays returns the date n working days after start, skipping
+// weekends.
+func addWorkingDays(start time.Time, n int) time.Time {
+	for start.Weekday() == time.Saturday || start.Weekday() == time.Sunday {
+		start = start.AddDate(0, 0, 1)
+	}
+	for n > 0 {
+		start = start.AddDate(0, 0, 1)
+		if start.Weekday() != time.Saturday && start.Weekday() != time.Sunday {
+			n--
+		}
+	}
+	return start
+}
+
+// scheduleIssues gives each issue a start and due date with --start-date.
+// Issues are scheduled in order, each starting once the issues it depends
+// on are done and its assignee is free, and taking its estimate at its
+// assignee's --capacity. Unassigned issues wait only on their
+// dependencies. Dates given on a ticket are kept.
+func scheduleIssues(tracker Tracker, issues []Issue) error {
+	if *scheduleStart == "" {
+		return nil
+	}
+	if *dueStart != "" {
+		return fmt.Errorf("--start-date and --due-start can't be used together")
+	}
+	start, err := time.Parse(dateLayout, *scheduleStart)
+	if err != nil {
+		return fmt.Errorf("--start-date must be YYYY-MM-DD: %v", err)
+	}
+
+	if *defaultCapacity <= 0 {
+		return fmt.Errorf("--default-capacity should be a number of hours")
+	}
+
+	// Assignees have been resolved by now, so --capacity has to be too.
+	capacities := make(map[string]float64, len(*capacity))
+	for user, c := range *capacity {
+		perDay, err := strconv.ParseFloat(c, 64)
+		if err != nil || perDay <= 0 {
+			return fmt.Errorf("--capacity %s=%s should be a number of hours", user, c)
+		}
+		if r, ok := tracker.(userResolver); ok {
+			if user, err = r.ResolveUser(user); err != nil {
+				return fmt.Errorf("--capacity: %v", err)
+			}
+		}
+		capacities[user] = perDay
+	}
+
+	// Times are in working days from the start date.
+	finished := make(map[string]float64)
+	free := make(map[string]float64)
+	last := 0.0
+	for i := range issues {
+		ticket := &issues[i].Ticket
+
+		hours := 0.0
+		if ticket.Estimate != "" {
+			hours, err = parseEstimate(ticket.Estimate)
+			if err != nil {
+				return fmt.Errorf("ticket %d: %v", issues[i].Index, err)
+			}
+		}
+		perDay, ok := capacities[ticket.Assignee]
+		if !ok {
+			perDay = *defaultCapacity
+		}
+
+		begin := 0.0
+		if ticket.Assignee != "" {
+			begin = free[ticket.Assignee]
+		}
+		for _, dep := range ticket.DependsOn {
+			begin = math.Max(begin, finished[dep])
+		}
+		end := begin + hours/perDay
+		if ticket.Assignee != "" {
+			free[ticket.Assignee] = end
+		}
+		if ticket.ID != "" {
+			finished[ticket.ID] = end
+		}
+		last = math.Max(last, end)
+
+		// Work ending exactly at the end of a day is due that day.
+		due := math.Max(math.Ceil(end)-1, math.Floor(begin))
+		if ticket.StartDate == "" {
+			ticket.StartDate = addWorkingDays(start, int(math.Floor(begin))).Format(dateLayout)
+		}
+		if ticket.DueDate == "" {
+			ticket.DueDate = addWorkingDays(start, int(due)).Format(dateLayout)
+		}
+	}
+
+	if len(issues) > 0 {
+		fmt.Printf(
+			"Scheduled %d ticket(s) over %.1f working day(s), to %s.\n",
+			len(issues),
+			last,
+			addWorkingDays(start, int(math.Max(math.Ceil(last)-1, 0))).Format(dateLayout),
+		)
+	}
+	return nil
+}
+
+// startDateField finds the field holding an issue's start date, which
+// Advanced Roadmaps calls Target start on some instances.
+func (t *jiraTracker) startDateField() (string, error) {
+	for _, name := range []string{"Start date", "Target start"} {
+		field, err := t.fieldID(name)
+		if err != nil || field != "" {
+			return field, err
+		}
+	}
+	return "", fmt.Errorf("there's no Start date or Target start field to set start dates with")
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseEstimate(t *testing.T) {
+	tests := []struct {
+		estimate string
+		hours    float64
+	}{
+		{"", 0},
+		{"3h", 3},
+		{"30m", 0.5},
+		{"1.5d", 12},
+		{"1w", 40},
+		{"1w 2d 4h 30m", 60.5},
+	}
+	for _, test := range tests {
+		hours, err := parseEstimate(test.estimate)
+		if err != nil {
+			t.Errorf("%q: %v", test.estimate, err)
+			continue
+		}
+		if hours != test.hours {
+			t.Errorf("%q: got %v hours, want %v", test.estimate, hours, test.hours)
+		}
+	}
+}
+
+func TestParseEstimateErrors(t *testing.T) {
+	for _, estimate := range []string{"3", "3x", "h", "two days", "1w2d"} {
+		if _, err := parseEstimate(estimate); err == nil {
+			t.Errorf("%q: expected an error", estimate)
+		}
+	}
+}
+
+func TestAddWorkingDays(t *testing.T) {
+	tests := []struct {
+		start string
+		n     int
+		want  string
+	}{
+		{"2024-07-01", 0, "2024-07-01"}, // Monday
+		{"2024-07-01", 4, "2024-07-05"},
+		{"2024-07-01", 5, "2024-07-08"},
+		{"2024-07-05", 1, "2024-07-08"}, // Friday
+		{"2024-07-06", 0, "2024-07-08"}, // Saturday
+		{"2024-07-07", 1, "2024-07-09"}, // Sunday
+		{"2024-07-01", 10, "2024-07-15"},
+	}
+	for _, test := range tests {
+		start, err := time.Parse(dateLayout, test.start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := addWorkingDays(start, test.n).Format(dateLayout); got != test.want {
+			t.Errorf("%s + %d working days: got %s, want %s", test.start, test.n, got, test.want)
+		}
+	}
+}
+
+func TestScheduleIssues(t *testing.T) {
+	defer func(start, due string, capacities map[string]string, dflt float64) {
+		*scheduleStart, *dueStart, *capacity, *defaultCapacity = start, due, capacities, dflt
+	}(*scheduleStart, *dueStart, *capacity, *defaultCapacity)
+	*scheduleStart = "2024-07-01"
+	*dueStart = ""
+	*capacity = map[string]string{"carol": "4"}
+	*defaultCapacity = 8
+
+	tickets := []Ticket{
+		{ID: "a", Assignee: "alice", Estimate: "2d"},
+		{DependsOn: []string{"a"}, Assignee: "bob", Estimate: "1d"},
+		{Assignee: "alice", Estimate: "4h"},
+		{Estimate: "1w"},
+		{Assignee: "carol", Estimate: "1d"},
+		{Assignee: "alice", Estimate: "1d", StartDate: "2024-08-01", DueDate: "2024-08-02"},
+	}
+	want := []struct{ start, due string }{
+		{"2024-07-01", "2024-07-02"},
+		// Waits for a.
+		{"2024-07-03", "2024-07-03"},
+		// Waits for alice to finish a.
+		{"2024-07-03", "2024-07-03"},
+		// Unassigned, so only waits on dependencies.
+		{"2024-07-01", "2024-07-05"},
+		// At 4 hours a day.
+		{"2024-07-01", "2024-07-02"},
+		// Given dates are kept.
+		{"2024-08-01", "2024-08-02"},
+	}
+
+	issues := make([]Issue, len(tickets))
+	for i, ticket := range tickets {
+		issues[i] = Issue{Index: i, Ticket: ticket}
+	}
+	if err := scheduleIssues(nil, issues); err != nil {
+		t.Fatal(err)
+	}
+	for i, issue := range issues {
+		if issue.Ticket.StartDate != want[i].start || issue.Ticket.DueDate != want[i].due {
+			t.Errorf(
+				"ticket %d: scheduled %s to %s, want %s to %s",
+				i, issue.Ticket.StartDate, issue.Ticket.DueDate, want[i].start, want[i].due,
+			)
+		}
+	}
+}
+
+func TestScheduleIssuesErrors(t *testing.T) {
+	defer func(start, due string, dflt float64) {
+		*scheduleStart, *dueStart, *defaultCapacity = start, due, dflt
+	}(*scheduleStart, *dueStart, *defaultCapacity)
+
+	tests := []struct {
+		name     string
+		start    string
+		due      string
+		capacity float64
+		estimate string
+	}{
+		{"both start and due dates", "2024-07-01", "2024-07-01", 8, ""},
+		{"bad start date", "July 1st", "", 8, ""},
+		{"no capacity", "2024-07-01", "", 0, ""},
+		{"bad estimate", "2024-07-01", "", 8, "soon"},
+	}
+	for _, test := range tests {
+		*scheduleStart, *dueStart, *defaultCapacity = test.start, test.due, test.capacity
+		issues := []Issue{{Ticket: Ticket{Estimate: test.estimate}}}
+		if err := scheduleIssues(nil, issues); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
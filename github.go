@@ -0,0 +1,265 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const milestonePrefix = "milestone:"
+
+var (
+	githubURL = kingpin.Flag(
+		"github-url",
+		"GitHub API URL, for the github backend. Change this for GitHub Enterprise.",
+	).Default("https://api.github.com").String()
+)
+
+// gitHubTracker creates issues in GitHub repositories. Tickets name their
+// repository as "owner/repo" in the project field. The epic is either a
+// tracking issue ("owner/repo#123"), to whose body a task list of the created
+// issues is appended, or a milestone ("milestone:<title>"), which is looked up
+// by title in each ticket's repository.
+type gitHubTracker struct {
+	*restClient
+	// milestones caches milestone numbers by repo and title.
+	milestones map[string]int
+}
+
+type gitHubIssue struct {
+	ID          int      `json:"id,omitempty"`
+	Number      int      `json:"number,omitempty"`
+	HTMLURL     string   `json:"html_url,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Body        string   `json:"body,omitempty"`
+	State       string   `json:"state,omitempty"`
+	StateReason string   `json:"state_reason,omitempty"`
+	Milestone   *int     `json:"milestone,omitempty"`
+	Assignees   []string `json:"assignees,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+type gitHubMilestone struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+}
+
+func init() {
+	registerTracker("github", newGitHubTracker)
+}
+
+func newGitHubTracker() Tracker {
+	creds, err := trackerCreds()
+	if err != nil {
+		panic(err)
+	}
+
+	header := make(http.Header)
+	header.Set("Accept", "application/vnd.github+json")
+	header.Set("Authorization", "Bearer "+creds.Password)
+	return &gitHubTracker{
+		restClient: newRESTClient(*githubURL, header),
+		milestones: make(map[string]int),
+	}
+}
+
+// parseGitHubRef splits an issue reference of the form "owner/repo#123".
+func parseGitHubRef(ref string) (string, int, error) {
+	repo, number, err := splitRef(ref, "#")
+	if err != nil || strings.Count(repo, "/") != 1 {
+		return "", 0, fmt.Errorf("%q is not of the form owner/repo#number", ref)
+	}
+	return repo, number, nil
+}
+
+func (t *gitHubTracker) ResolveEpic(name string) (*Epic, error) {
+	if strings.HasPrefix(name, milestonePrefix) {
+		return &Epic{Key: name}, nil
+	}
+
+	repo, number, err := parseGitHubRef(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var issue gitHubIssue
+	err = t.do("GET", fmt.Sprintf("/repos/%s/issues/%d", repo, number), nil, &issue)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Epic{
+		ID:      strconv.Itoa(issue.ID),
+		Key:     name,
+		URL:     issue.HTMLURL,
+		Project: repo,
+		Status:  issue.State,
+		Closed:  issue.State == "closed",
+	}, nil
+}
+
+func (t *gitHubTracker) milestone(repo string, title string) (int, error) {
+	key := repo + " " + title
+	if number, ok := t.milestones[key]; ok {
+		return number, nil
+	}
+
+	for page := 1; ; page++ {
+		var milestones []gitHubMilestone
+		path := fmt.Sprintf("/repos/%s/milestones?state=all&per_page=100&page=%d", repo, page)
+		if err := t.do("GET", path, nil, &milestones); err != nil {
+			return 0, err
+		}
+		if len(milestones) == 0 {
+			return 0, fmt.Errorf("no milestone titled %q in %s", title, repo)
+		}
+
+		for _, milestone := range milestones {
+			if milestone.Title == title {
+				t.milestones[key] = milestone.Number
+				return milestone.Number, nil
+			}
+		}
+	}
+}
+
+func (t *gitHubTracker) CreateIssues(epic *Epic, issues []Issue, run *Run) error {
+	created := make([]string, 0, len(issues))
+	err := t.create(epic, issues, run, &created)
+
+	// Attach whatever was created, even if the run failed partway through,
+	// so the tracking issue reflects what actually exists.
+	if !strings.HasPrefix(epic.Key, milestonePrefix) && len(created) > 0 {
+		if trackErr := t.appendToTrackingIssue(epic.Key, created); trackErr != nil && err == nil {
+			err = trackErr
+		}
+	}
+	return err
+}
+
+func (t *gitHubTracker) create(epic *Epic, issues []Issue, run *Run, created *[]string) error {
+	for _, issue := range issues {
+		if err := checkStopped(); err != nil {
+			return err
+		}
+		repo := issue.Ticket.Project
+		payload := gitHubIssue{
+			Title:  issue.Summary,
+			Body:   issue.Description,
+			Labels: issue.Ticket.allLabels(),
+		}
+		if issue.Ticket.Assignee != "" {
+			payload.Assignees = []string{issue.Ticket.Assignee}
+		}
+
+		if strings.HasPrefix(epic.Key, milestonePrefix) {
+			number, err := t.milestone(repo, strings.TrimPrefix(epic.Key, milestonePrefix))
+			if err != nil {
+				return err
+			}
+			payload.Milestone = &number
+		}
+
+		var result gitHubIssue
+		if err := t.do("POST", fmt.Sprintf("/repos/%s/issues", repo), &payload, &result); err != nil {
+			return err
+		}
+
+		key := fmt.Sprintf("%s#%d", repo, result.Number)
+		printCreated(key, issue.Summary, result.HTMLURL)
+		*created = append(*created, key)
+
+		err := run.Record(CreatedIssue{
+			ID:      strconv.Itoa(result.ID),
+			Key:     key,
+			Project: repo,
+			Summary: issue.Summary,
+			Ticket:  issue.Index,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *gitHubTracker) appendToTrackingIssue(ref string, keys []string) error {
+	repo, number, err := parseGitHubRef(ref)
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/repos/%s/issues/%d", repo, number)
+	var issue gitHubIssue
+	if err := t.do("GET", path, nil, &issue); err != nil {
+		return err
+	}
+
+	body := strings.TrimRight(issue.Body, "\n") + "\n"
+	for _, key := range keys {
+		body += fmt.Sprintf("- [ ] %s\n", key)
+	}
+	return t.do("PATCH", path, &gitHubIssue{Body: body}, nil)
+}
+
+// LinkIssues comments on from, naming to. GitHub has no issue links, but the
+// reference shows up in both issues' timelines.
+func (t *gitHubTracker) LinkIssues(from string, to string, kind string) error {
+	return t.AddComment(from, fmt.Sprintf("This issue %s %s.", kind, to))
+}
+
+func (t *gitHubTracker) AddComment(key string, body string) error {
+	repo, number, err := parseGitHubRef(key)
+	if err != nil {
+		return err
+	}
+
+	comment := map[string]string{"body": body}
+	return t.do("POST", fmt.Sprintf("/repos/%s/issues/%d/comments", repo, number), comment, nil)
+}
+
+func (t *gitHubTracker) GetDescription(key string) (string, error) {
+	repo, number, err := parseGitHubRef(key)
+	if err != nil {
+		return "", err
+	}
+
+	var issue gitHubIssue
+	err = t.do("GET", fmt.Sprintf("/repos/%s/issues/%d", repo, number), nil, &issue)
+	return issue.Body, err
+}
+
+func (t *gitHubTracker) SetDescription(key string, description string) error {
+	repo, number, err := parseGitHubRef(key)
+	if err != nil {
+		return err
+	}
+	return t.do("PATCH", fmt.Sprintf("/repos/%s/issues/%d", repo, number), &gitHubIssue{Body: description}, nil)
+}
+
+func (t *gitHubTracker) setState(key string, reason string) error {
+	repo, number, err := parseGitHubRef(key)
+	if err != nil {
+		return err
+	}
+
+	update := gitHubIssue{State: "closed", StateReason: reason}
+	return t.do("PATCH", fmt.Sprintf("/repos/%s/issues/%d", repo, number), &update, nil)
+}
+
+// DeleteIssue closes the issue as "not planned", since GitHub's REST API
+// does not allow issues to be deleted.
+func (t *gitHubTracker) DeleteIssue(key string) error {
+	return t.setState(key, "not_planned")
+}
+
+// CloseIssue closes the issue as completed. GitHub has no workflow, so the
+// transition is ignored.
+func (t *gitHubTracker) CloseIssue(key string, transition string) error {
+	return t.setState(key, "completed")
+}
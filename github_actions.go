@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// appendFile appends to the file, creating it if need be.
+func appendFile(p string, data []byte) error {
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeGitHubOutputs sets the run's outputs when running as a GitHub Actions
+// step, so later steps can use the created keys, and adds a summary of the
+// run to the step's page.
+func writeGitHubOutputs(epic *Epic, run *Run) error {
+	if os.Getenv("GITHUB_ACTIONS") != "true" {
+		return nil
+	}
+
+	epicURL := ""
+	if epic.Key != "" {
+		epicURL = epicLink(epic)
+	}
+	if p := os.Getenv("GITHUB_OUTPUT"); p != "" {
+		buf := bytes.NewBufferString("")
+		fmt.Fprintf(buf, "run-id=%s\n", run.ID)
+		fmt.Fprintf(buf, "epic=%s\n", run.Epic)
+		fmt.Fprintf(buf, "epic-url=%s\n", epicURL)
+		fmt.Fprintf(buf, "keys=%s\n", strings.Join(createdKeys(run.Created), " "))
+		fmt.Fprintf(buf, "created=%d\n", len(run.Created))
+		if err := appendFile(p, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	if p := os.Getenv("GITHUB_STEP_SUMMARY"); p != "" {
+		if err := appendFile(p, []byte(stepSummary(epicURL, run))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stepSummary is the run as Markdown, for the GitHub Actions run page.
+func stepSummary(epicURL string, run *Run) string {
+	buf := bytes.NewBufferString("")
+	epic := run.Epic
+	if epicURL != "" {
+		epic = fmt.Sprintf("[%s](%s)", run.Epic, epicURL)
+	}
+	fmt.Fprintf(buf, "### epic-creator run %s\n\n", run.ID)
+	if run.Epic != "" {
+		fmt.Fprintf(buf, "Created %d issue(s) in %s.\n\n", len(run.Created), epic)
+	} else {
+		fmt.Fprintf(buf, "Created %d issue(s).\n\n", len(run.Created))
+	}
+	if run.Error != "" {
+		fmt.Fprintf(buf, "> **The run failed:** %s\n\n", strings.Replace(run.Error, "\n", "\n> ", -1))
+	}
+	if len(run.Created) > 0 {
+		buf.WriteString("| Key | Summary |\n| --- | --- |\n")
+		for _, created := range run.Created {
+			key := created.Key
+			if link := issueLink(created.Key); link != "" {
+				key = fmt.Sprintf("[%s](%s)", created.Key, link)
+			}
+			fmt.Fprintf(buf, "| %s | %s |\n", key, strings.Replace(created.Summary, "|", "\\|", -1))
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
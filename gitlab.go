@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	gitlabURL = kingpin.Flag(
+		"gitlab-url",
+		"GitLab instance URL, for the gitlab backend.",
+	).Default("https://gitlab.com").String()
+)
+
+// gitLabTracker creates issues in GitLab projects. Tickets name their project
+// by its full path ("group/project") in the project field, and the epic is a
+// group epic reference ("group&123"). Created issues are added to the epic
+// through the epic issues API.
+type gitLabTracker struct {
+	*restClient
+	users map[string]int
+}
+
+type gitLabIssue struct {
+	ID          int    `json:"id,omitempty"`
+	IID         int    `json:"iid,omitempty"`
+	WebURL      string `json:"web_url,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	DueDate     string `json:"due_date,omitempty"`
+	Weight      int    `json:"weight,omitempty"`
+	AssigneeIDs []int  `json:"assignee_ids,omitempty"`
+	Labels      string `json:"labels,omitempty"`
+	StateEvent  string `json:"state_event,omitempty"`
+}
+
+type gitLabEpic struct {
+	ID          int    `json:"id,omitempty"`
+	IID         int    `json:"iid,omitempty"`
+	WebURL      string `json:"web_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	State       string `json:"state,omitempty"`
+}
+
+// gitLabLinkTypes maps the kinds of link known to Tracker onto GitLab's
+// issue link types.
+var gitLabLinkTypes = map[string]string{
+	linkRelates: "relates_to",
+	linkBlocks:  "blocks",
+}
+
+func init() {
+	registerTracker("gitlab", newGitLabTracker)
+}
+
+func newGitLabTracker() Tracker {
+	creds, err := trackerCreds()
+	if err != nil {
+		panic(err)
+	}
+
+	header := make(http.Header)
+	header.Set("PRIVATE-TOKEN", creds.Password)
+	return &gitLabTracker{
+		restClient: newRESTClient(*gitlabURL+"/api/v4", header),
+		users:      make(map[string]int),
+	}
+}
+
+func (t *gitLabTracker) ResolveEpic(name string) (*Epic, error) {
+	group, iid, err := splitRef(name, "&")
+	if err != nil {
+		return nil, err
+	}
+
+	var epic gitLabEpic
+	err = t.do("GET", fmt.Sprintf("/groups/%s/epics/%d", url.PathEscape(group), iid), nil, &epic)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Epic{
+		ID:     strconv.Itoa(epic.ID),
+		Key:    name,
+		URL:    epic.WebURL,
+		Status: epic.State,
+		Closed: epic.State == "closed",
+	}, nil
+}
+
+func (t *gitLabTracker) userID(username string) (int, error) {
+	if id, ok := t.users[username]; ok {
+		return id, nil
+	}
+
+	var users []struct {
+		ID int `json:"id"`
+	}
+	if err := t.do("GET", "/users?username="+url.QueryEscape(username), nil, &users); err != nil {
+		return 0, err
+	}
+	if len(users) == 0 {
+		return 0, fmt.Errorf("no GitLab user named %q", username)
+	}
+
+	t.users[username] = users[0].ID
+	return users[0].ID, nil
+}
+
+func (t *gitLabTracker) CreateIssues(epic *Epic, issues []Issue, run *Run) error {
+	group, epicIID, err := splitRef(epic.Key, "&")
+	if err != nil {
+		return err
+	}
+
+	for _, issue := range issues {
+		if err := checkStopped(); err != nil {
+			return err
+		}
+		project := issue.Ticket.Project
+		payload := gitLabIssue{
+			Title:       issue.Summary,
+			Description: issue.Description,
+			DueDate:     issue.Ticket.DueDate,
+			Weight:      int(issue.Ticket.StoryPoints),
+			Labels:      strings.Join(issue.Ticket.allLabels(), ","),
+		}
+		if issue.Ticket.Assignee != "" {
+			id, err := t.userID(issue.Ticket.Assignee)
+			if err != nil {
+				return err
+			}
+			payload.AssigneeIDs = []int{id}
+		}
+
+		var result gitLabIssue
+		err := t.do("POST", fmt.Sprintf("/projects/%s/issues", url.PathEscape(project)), &payload, &result)
+		if err != nil {
+			return err
+		}
+
+		key := fmt.Sprintf("%s#%d", project, result.IID)
+		printCreated(key, issue.Summary, result.WebURL)
+		err = run.Record(CreatedIssue{
+			ID:      strconv.Itoa(result.ID),
+			Key:     key,
+			Project: project,
+			Summary: issue.Summary,
+			Ticket:  issue.Index,
+		})
+		if err != nil {
+			return err
+		}
+
+		path := fmt.Sprintf("/groups/%s/epics/%d/issues/%d", url.PathEscape(group), epicIID, result.ID)
+		if err := t.do("POST", path, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *gitLabTracker) LinkIssues(from string, to string, kind string) error {
+	linkType, ok := gitLabLinkTypes[kind]
+	if !ok {
+		return fmt.Errorf("unknown link kind %q", kind)
+	}
+
+	project, iid, err := splitRef(from, "#")
+	if err != nil {
+		return err
+	}
+	targetProject, targetIID, err := splitRef(to, "#")
+	if err != nil {
+		return err
+	}
+
+	link := map[string]string{
+		"target_project_id": targetProject,
+		"target_issue_iid":  strconv.Itoa(targetIID),
+		"link_type":         linkType,
+	}
+	return t.do("POST", fmt.Sprintf("/projects/%s/issues/%d/links", url.PathEscape(project), iid), link, nil)
+}
+
+func (t *gitLabTracker) AddComment(key string, body string) error {
+	project, iid, err := splitRef(key, "#")
+	if err != nil {
+		return err
+	}
+
+	note := map[string]string{"body": body}
+	return t.do("POST", fmt.Sprintf("/projects/%s/issues/%d/notes", url.PathEscape(project), iid), note, nil)
+}
+
+// descriptionPath returns the API path of the group epic (group&iid) or
+// project issue (project#iid) whose description is read and rewritten.
+func descriptionPath(key string) (string, error) {
+	if strings.Contains(key, "&") {
+		group, iid, err := splitRef(key, "&")
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("/groups/%s/epics/%d", url.PathEscape(group), iid), nil
+	}
+	project, iid, err := splitRef(key, "#")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("/projects/%s/issues/%d", url.PathEscape(project), iid), nil
+}
+
+// GetDescription reads the description of a group epic or an issue.
+func (t *gitLabTracker) GetDescription(key string) (string, error) {
+	path, err := descriptionPath(key)
+	if err != nil {
+		return "", err
+	}
+
+	// Epics and issues both hold it in description.
+	var item gitLabEpic
+	err = t.do("GET", path, nil, &item)
+	return item.Description, err
+}
+
+// SetDescription rewrites the description of a group epic or an issue.
+func (t *gitLabTracker) SetDescription(key string, description string) error {
+	path, err := descriptionPath(key)
+	if err != nil {
+		return err
+	}
+
+	update := map[string]string{"description": description}
+	return t.do("PUT", path, &update, nil)
+}
+
+func (t *gitLabTracker) DeleteIssue(key string) error {
+	project, iid, err := splitRef(key, "#")
+	if err != nil {
+		return err
+	}
+	return t.do("DELETE", fmt.Sprintf("/projects/%s/issues/%d", url.PathEscape(project), iid), nil, nil)
+}
+
+// CloseIssue closes the issue. GitLab has no workflow, so the transition is
+// ignored.
+func (t *gitLabTracker) CloseIssue(key string, transition string) error {
+	project, iid, err := splitRef(key, "#")
+	if err != nil {
+		return err
+	}
+
+	update := gitLabIssue{StateEvent: "close"}
+	return t.do("PUT", fmt.Sprintf("/projects/%s/issues/%d", url.PathEscape(project), iid), &update, nil)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+)
+
+var (
+	globalsPath = createCmd.Flag(
+		"globals",
+		"Path to a JSON, YAML, Jsonnet or CUE object of run-wide values, available to every template as .Globals, overriding the tickets file's.",
+	).ExistingFile()
+)
+
+// globals are the run-wide values templates see as .Globals: those of the
+// tickets file, then --globals. loadTickets sets them.
+var globals = make(map[string]interface{})
+
+// extractGlobals takes the entries of the tickets which hold globals out of
+// them, merging their globals in order, then adds --globals. The globals of
+// an earlier run, under --watch or serve, are dropped.
+func extractGlobals(tickets []Ticket) ([]Ticket, error) {
+	globals = make(map[string]interface{})
+	entries := tickets
+	tickets = make([]Ticket, 0, len(entries))
+	for i, entry := range entries {
+		if entry.Globals == nil {
+			tickets = append(tickets, entry)
+			continue
+		}
+		if entry.Project != "" || len(entry.Params) > 0 {
+			return nil, fmt.Errorf("ticket %d: an entry with globals can't also be a ticket", i)
+		}
+		for k, v := range entry.Globals {
+			globals[k] = v
+		}
+	}
+
+	if *globalsPath != "" {
+		fromFile, err := loadParamsFile(*globalsPath)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fromFile {
+			globals[k] = v
+		}
+	}
+	return tickets, nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ticketsGSheet = createCmd.Flag(
+		"tickets-gsheet",
+		"Google Sheet to read tickets from instead of --tickets-json, as <sheet-id>!<range>, e.g. 1AbC...!Tickets!A1:F. The first row is the header, mapped as for CSV with --csv-mapping.",
+	).String()
+	gsheetCredentials = createCmd.Flag(
+		"gsheet-credentials",
+		"Path to the JSON key of the Google service account to read --tickets-gsheet with. The sheet must be shared with the account.",
+	).Envar("GOOGLE_APPLICATION_CREDENTIALS").String()
+)
+
+const (
+	sheetsAPI   = "https://sheets.googleapis.com/v4/spreadsheets"
+	sheetsScope = "https://www.googleapis.com/auth/spreadsheets.readonly"
+)
+
+// serviceAccountKey is the part of a Google service account's JSON key
+// needed to sign in as it.
+type serviceAccountKey struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// sheetValues is the response of the Sheets API's values.get.
+type sheetValues struct {
+	Values [][]string `json:"values"`
+}
+
+// loadSheetTickets reads tickets from --tickets-gsheet, just as loadTickets
+// reads them from a file.
+func loadSheetTickets(sheet string) ([]Ticket, error) {
+	tickets, err := gsheetTickets(sheet, *csvMappingPath)
+	if err != nil {
+		return nil, err
+	}
+	// A sheet has no directory of its own, so params files are relative
+	// to the working directory.
+	for i := range tickets {
+		if err := mergeParamsFile(&tickets[i], "."); err != nil {
+			return nil, fmt.Errorf("%s: row %d: %v", sheet, i+2, err)
+		}
+	}
+	return prepareTickets(tickets)
+}
+
+// gsheetTickets reads a range of a Google Sheet, whose first row is its
+// header, into a ticket per row.
+func gsheetTickets(sheet string, mappingPath string) ([]Ticket, error) {
+	parts := strings.SplitN(sheet, "!", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("--tickets-gsheet %q isn't <sheet-id>!<range>", sheet)
+	}
+	mapping, err := loadCSVMapping(mappingPath)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := serviceAccountToken(*gsheetCredentials, sheetsScope)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/%s/values/%s", sheetsAPI, url.PathEscape(parts[0]), url.PathEscape(parts[1])), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var values sheetValues
+	if err := doGoogle(req, &values); err != nil {
+		return nil, fmt.Errorf("reading %s: %v", sheet, err)
+	}
+	return rowTickets(sheet, mapping, values.Values)
+}
+
+// serviceAccountToken exchanges a JWT signed with the service account's key
+// for an access token with the scope.
+func serviceAccountToken(keyPath string, scope string) (string, error) {
+	if keyPath == "" {
+		return "", fmt.Errorf("--gsheet-credentials or GOOGLE_APPLICATION_CREDENTIALS is required")
+	}
+	data, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return "", err
+	}
+	var key serviceAccountKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return "", fmt.Errorf("%s: %v", keyPath, err)
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	assertion, err := signJWT(key, scope)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", keyPath, err)
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequest("POST", key.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doGoogle(req, &token); err != nil {
+		return "", fmt.Errorf("signing in as %s: %v", key.ClientEmail, err)
+	}
+	return token.AccessToken, nil
+}
+
+// signJWT returns a JWT asserting the service account, for an hour, signed
+// with RS256.
+func signJWT(key serviceAccountKey, scope string) (string, error) {
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("private_key isn't PEM")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+	private, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("private_key isn't an RSA key")
+	}
+
+	now := time.Now()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   key.ClientEmail,
+		"scope": scope,
+		"aud":   key.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, private, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// doGoogle sends a request to a Google API and decodes the JSON response.
+func doGoogle(req *http.Request, v interface{}) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	recordPath = kingpin.Flag(
+		"record",
+		"Record every JIRA request and response to this HAR file.",
+	).String()
+	replayPath = kingpin.Flag(
+		"replay",
+		"Answer JIRA requests from a HAR file made with --record, instead of the instance.",
+	).ExistingFile()
+)
+
+// The subset of the HAR 1.2 format (http://www.softwareishard.com/blog/har-12-spec/)
+// needed to replay a session.
+type harFile struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harRequest struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Headers     []harHeader `json:"headers"`
+	QueryString []harHeader `json:"queryString"`
+	PostData    *harContent `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Headers     []harHeader `json:"headers"`
+	Content     harContent  `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// harSecretHeaders are left out of recordings, which are meant to be shared.
+var harSecretHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+}
+
+// harRedactedBody stands in for the bodies of logins, which hold the
+// password and the session cookie. It's still JSON, so a replayed login
+// succeeds.
+const harRedactedBody = `{"redacted": "login bodies are left out of recordings"}`
+
+// harSecretBody reports whether the bodies of requests to u are left out of
+// recordings: those of JIRA's session login, for --jira-auth session.
+func harSecretBody(u *neturl.URL) bool {
+	return strings.Contains(u.Path, "/rest/auth/")
+}
+
+func harHeaders(header http.Header) []harHeader {
+	headers := make([]harHeader, 0, len(header))
+	for name, values := range header {
+		if harSecretHeaders[http.CanonicalHeaderKey(name)] {
+			continue
+		}
+		for _, value := range values {
+			headers = append(headers, harHeader{Name: name, Value: value})
+		}
+	}
+	return headers
+}
+
+// recordingTransport passes requests through to another transport, writing
+// each exchange to a HAR file as it completes, so a run which fails partway
+// through is still recorded.
+type recordingTransport struct {
+	next http.RoundTripper
+	path string
+
+	mu  sync.Mutex
+	har harFile
+}
+
+func newRecordingTransport(next http.RoundTripper, path string) *recordingTransport {
+	return &recordingTransport{
+		next: next,
+		path: path,
+		har: harFile{Log: harLog{
+			Version: "1.2",
+			Creator: harCreator{Name: "epic-creator"},
+			Entries: make([]harEntry, 0),
+		}},
+	}
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	started := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+	elapsed := float64(time.Since(started)) / float64(time.Millisecond)
+
+	entry := harEntry{
+		StartedDateTime: started,
+		Time:            elapsed,
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Headers:     harHeaders(req.Header),
+			QueryString: make([]harHeader, 0),
+			HeadersSize: -1,
+			BodySize:    len(reqBody),
+		},
+		Response: harResponse{
+			Status:      resp.StatusCode,
+			StatusText:  http.StatusText(resp.StatusCode),
+			HTTPVersion: resp.Proto,
+			Headers:     harHeaders(resp.Header),
+			Content: harContent{
+				Size:     len(respBody),
+				MimeType: resp.Header.Get("Content-Type"),
+				Text:     string(respBody),
+			},
+			HeadersSize: -1,
+			BodySize:    len(respBody),
+		},
+		Timings: harTimings{Wait: elapsed},
+	}
+	if harSecretBody(req.URL) {
+		if reqBody != nil {
+			reqBody = []byte(harRedactedBody)
+			entry.Request.BodySize = len(reqBody)
+		}
+		entry.Response.Content.Text = harRedactedBody
+		entry.Response.Content.Size = len(harRedactedBody)
+		entry.Response.BodySize = len(harRedactedBody)
+	}
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			entry.Request.QueryString = append(entry.Request.QueryString, harHeader{Name: name, Value: value})
+		}
+	}
+	if reqBody != nil {
+		entry.Request.PostData = &harContent{
+			Size:     len(reqBody),
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     string(reqBody),
+		}
+	}
+
+	if err := t.save(entry); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (t *recordingTransport) save(entry harEntry) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.har.Log.Entries = append(t.har.Log.Entries, entry)
+	data, err := json.MarshalIndent(&t.har, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(t.path, data, 0644)
+}
+
+// replayingTransport answers requests from a recording. Each request gets
+// the response to the first request in the recording with the same method
+// and URL which hasn't already been replayed, so repeated requests replay
+// in order.
+type replayingTransport struct {
+	mu      sync.Mutex
+	entries []harEntry
+	used    []bool
+}
+
+func newReplayingTransport(path string) (*replayingTransport, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &replayingTransport{
+		entries: har.Log.Entries,
+		used:    make([]bool, len(har.Log.Entries)),
+	}, nil
+}
+
+func (t *replayingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	url := req.URL.String()
+	for i, entry := range t.entries {
+		if t.used[i] || entry.Request.Method != req.Method || entry.Request.URL != url {
+			continue
+		}
+		t.used[i] = true
+
+		header := make(http.Header)
+		for _, h := range entry.Response.Headers {
+			header.Add(h.Name, h.Value)
+		}
+		body := entry.Response.Content.Text
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", entry.Response.Status, entry.Response.StatusText),
+			StatusCode:    entry.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewBufferString(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("no recorded response left for %s %s", req.Method, url)
+}
+
+// configureJIRARecording sets jiraTransport from --record or --replay.
+func configureJIRARecording() error {
+	if *recordPath != "" && *replayPath != "" {
+		return fmt.Errorf("--record and --replay can't be used together")
+	}
+	if *recordPath != "" {
+		jiraTransport = newRecordingTransport(http.DefaultTransport, *recordPath)
+	}
+	if *replayPath != "" {
+		replay, err := newReplayingTransport(*replayPath)
+		if err != nil {
+			return err
+		}
+		jiraTransport = replay
+	}
+	return nil
+}
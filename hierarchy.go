@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/trivago/tgo/tcontainer"
+	jira "gopkg.in/andygrunwald/go-jira.v1"
+)
+
+// hierarchyTicket is the ticket index recorded for issues created from
+// --hierarchy rather than the tickets file.
+const hierarchyTicket = -1
+
+// Issue types of the levels of a hierarchy above stories.
+const (
+	initiativeType = "Initiative"
+	epicType       = "Epic"
+)
+
+var (
+	hierarchyPath = createCmd.Flag(
+		"hierarchy",
+		"Path to a JSON file of epics, and optionally an initiative for them, to create before the tickets.",
+	).ExistingFile()
+)
+
+// hierarchyNode is an initiative or epic to create. Tickets name the epics
+// they belong in by the epic's name.
+type hierarchyNode struct {
+	Name        string `json:"name"`
+	Project     string `json:"project"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+	// EpicName is the template for the Epic Name field classic projects
+	// require, which defaults to the summary.
+	EpicName string `json:"epic_name"`
+	// Params are available to the node's templates, over the hierarchy's
+	// own.
+	Params map[string]interface{} `json:"params"`
+	// Colour is the epic's colour on boards, from 1 to 14.
+	Colour string `json:"color"`
+	// Team is the name or ID of the Advanced Roadmaps team the epic
+	// belongs to.
+	Team string `json:"team"`
+	// Fields are other fields to set, by name or ID.
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// hierarchyContext is the context the summary and description of a
+// hierarchy node are executed with as templates.
+type hierarchyContext struct {
+	// Date is when the run was scheduled for with --schedule, and when it
+	// started otherwise.
+	Date time.Time
+	// Params are the hierarchy's params, overridden by the node's and then
+	// by those given with --set.
+	Params map[string]interface{}
+	// Globals are the run's globals, as ticket templates have them.
+	Globals map[string]interface{}
+}
+
+func newHierarchyContext(h *hierarchy, started time.Time) hierarchyContext {
+	ctx := hierarchyContext{Date: started, Params: make(map[string]interface{}), Globals: globals}
+	if !scheduledAt.IsZero() {
+		ctx.Date = scheduledAt
+	}
+	for k, v := range h.Params {
+		ctx.Params[k] = v
+	}
+	return ctx
+}
+
+// render executes the node's summary, description and epic name as
+// templates.
+func (n hierarchyNode) render(ctx hierarchyContext) (hierarchyNode, error) {
+	params := ctx.Params
+	ctx.Params = make(map[string]interface{}, len(params)+len(n.Params))
+	for k, v := range params {
+		ctx.Params[k] = v
+	}
+	for k, v := range n.Params {
+		ctx.Params[k] = v
+	}
+	for k, v := range *setParams {
+		ctx.Params[k] = v
+	}
+
+	if n.EpicName == "" {
+		n.EpicName = n.Summary
+	}
+	for _, text := range []*string{&n.Summary, &n.Description, &n.EpicName} {
+		tmpl, err := newTemplate(n.Name).Parse(*text)
+		if err != nil {
+			return n, err
+		}
+		buf := bytes.NewBufferString("")
+		if err := tmpl.Execute(buf, ctx); err != nil {
+			return n, err
+		}
+		*text = buf.String()
+	}
+	return n, nil
+}
+
+// hierarchy is the contents of a --hierarchy file. The epics are created
+// under Parent, an existing initiative, or under Initiative, which is
+// created first.
+type hierarchy struct {
+	Parent     string          `json:"parent"`
+	Initiative *hierarchyNode  `json:"initiative"`
+	Epics      []hierarchyNode `json:"epics"`
+	// Params are available to the templates of every node.
+	Params map[string]interface{} `json:"params"`
+}
+
+// epicCreator is implemented by trackers which can create epics and the
+// initiatives above them.
+type epicCreator interface {
+	// CreateEpic creates an issue of the given type, an initiative or an
+	// epic, under parent if it isn't empty, and records it in the run.
+	CreateEpic(issueType string, node hierarchyNode, parent string, run *Run) (*Epic, error)
+}
+
+func loadHierarchy(p string) (*hierarchy, error) {
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &hierarchy{}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, err
+	}
+	if h.Parent != "" && h.Initiative != nil {
+		return nil, fmt.Errorf("%s: give either a parent or an initiative, not both", p)
+	}
+	for _, node := range h.Epics {
+		if node.Name == "" {
+			return nil, fmt.Errorf("%s: epic %q has no name for tickets to refer to it by", p, node.Summary)
+		}
+	}
+	return h, nil
+}
+
+// createHierarchy creates the hierarchy's initiative, if it has one, and
+// its epics, adding the epics to the resolver under their names.
+func createHierarchy(tracker Tracker, h *hierarchy, epics *epicResolver, run *Run) error {
+	c, ok := tracker.(epicCreator)
+	if !ok {
+		return fmt.Errorf("backend %s does not support creating epics", *backend)
+	}
+
+	ctx := newHierarchyContext(h, run.Started)
+
+	parent := h.Parent
+	if h.Initiative != nil {
+		node, err := h.Initiative.render(ctx)
+		if err != nil {
+			return fmt.Errorf("initiative: %v", err)
+		}
+		initiative, err := c.CreateEpic(initiativeType, node, "", run)
+		if err != nil {
+			return err
+		}
+		if err := tagHierarchyIssue(tracker, run, node); err != nil {
+			return err
+		}
+		parent = initiative.Key
+	}
+
+	for _, node := range h.Epics {
+		node, err := node.render(ctx)
+		if err != nil {
+			return fmt.Errorf("epic %q: %v", node.Name, err)
+		}
+		epic, err := c.CreateEpic(epicType, node, parent, run)
+		if err != nil {
+			return err
+		}
+		if err := tagHierarchyIssue(tracker, run, node); err != nil {
+			return err
+		}
+		epics.Add(node.Name, epic)
+	}
+	return nil
+}
+
+// tagHierarchyIssue sets the run property on the epic or initiative just
+// created from the node, as the post-create steps do for tickets.
+func tagHierarchyIssue(tracker Tracker, run *Run, node hierarchyNode) error {
+	created := run.Created[len(run.Created)-1]
+	issue := Issue{Summary: node.Summary, Description: node.Description}
+	if err := tagWithRun(tracker, run, issue, created); err != nil {
+		return fmt.Errorf("%s: %v", created.Key, err)
+	}
+	return nil
+}
+
+// fieldID finds the ID of a field by its name, fetching the field list on
+// first use.
+func (t *jiraTracker) fieldID(name string) (string, error) {
+	fields, err := t.allFields()
+	if err != nil {
+		return "", err
+	}
+
+	for _, field := range fields {
+		if strings.EqualFold(field.Name, name) {
+			return field.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// CreateEpic creates an initiative or epic. Where Advanced Roadmaps is
+// installed, the parent is set through its Parent Link field; otherwise it
+// is set as the issue's parent, as Jira Cloud's issue hierarchy expects.
+func (t *jiraTracker) CreateEpic(issueType string, node hierarchyNode, parent string, run *Run) (*Epic, error) {
+	project, err := t.project(node.Project, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *jira.IssueType
+	for i := range project.IssueTypes {
+		if strings.EqualFold(project.IssueTypes[i].Name, issueType) {
+			found = &project.IssueTypes[i]
+			break
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("project %s has no %s issue type", node.Project, issueType)
+	}
+
+	description := node.Description
+	var adf *adfNode
+	if useADF() {
+		doc := descriptionToADF(description, *descriptionFormat)
+		adf = &doc
+	} else if *descriptionFormat == "markdown" {
+		description = markdownToWiki(description)
+	}
+
+	fields := jira.IssueFields{
+		Summary:     node.Summary,
+		Description: description,
+		Type:        *found,
+		Project:     *project,
+		Unknowns:    tcontainer.MarshalMap{},
+	}
+	if issueType == epicType {
+		// JIRA Server requires epics to have an Epic Name.
+		epicName, err := t.fieldID("Epic Name")
+		if err != nil {
+			return nil, err
+		}
+		if epicName != "" {
+			fields.Unknowns[epicName] = node.EpicName
+		}
+	}
+	colour, err := t.setEpicFields(node, fields.Unknowns)
+	if err != nil {
+		return nil, err
+	}
+	if parent != "" {
+		parentLink, err := t.fieldID("Parent Link")
+		if err != nil {
+			return nil, err
+		}
+		if parentLink != "" {
+			fields.Unknowns[parentLink] = parent
+		} else {
+			fields.Parent = &jira.Parent{Key: parent}
+		}
+	}
+
+	created, err := createIssue(t.client, pendingIssue{
+		index:  hierarchyTicket,
+		ticket: Ticket{Project: node.Project},
+		issue:  jira.Issue{Fields: &fields},
+		adf:    adf,
+	}, run)
+	if err != nil {
+		return nil, err
+	}
+	if colour != 0 {
+		if err := t.setEpicColour(created.Key, colour); err != nil {
+			return nil, err
+		}
+	}
+	// Give tickets the same details of the epic as one named on the
+	// command line, so project inference and .Epic work the same.
+	return &Epic{
+		ID:          created.ID,
+		Key:         created.Key,
+		URL:         created.Self,
+		Project:     project.Key,
+		Summary:     node.Summary,
+		Description: node.Description,
+		Fields:      node.Fields,
+	}, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// hookIssue is how a rendered issue is passed to hooks.
+type hookIssue struct {
+	Index       int    `json:"index"`
+	Ticket      Ticket `json:"ticket"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+}
+
+// hookCreated is how a created issue is passed to post-create hooks.
+type hookCreated struct {
+	hookIssue
+	Created CreatedIssue `json:"created"`
+}
+
+func newHookIssue(issue Issue) hookIssue {
+	return hookIssue{
+		Index:       issue.Index,
+		Ticket:      issue.Ticket,
+		Summary:     issue.Summary,
+		Description: issue.Description,
+	}
+}
+
+// runHook runs command through the shell with payload, as JSON, on its
+// stdin. The hook's output is passed through, and it failing is an error.
+func runHook(command string, payload interface{}, run *Run, index int) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(
+		os.Environ(),
+		"EPIC_CREATOR_RUN_ID="+run.ID,
+		"EPIC_CREATOR_EPIC="+run.Epic,
+		"EPIC_CREATOR_TICKET_INDEX="+strconv.Itoa(index),
+	)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("hook %q: %v", command, err)
+	}
+	return nil
+}
+
+// runPreCreateHooks runs --pre-create-hook for each rendered issue, before
+// any are created, so a failing hook stops the run before it's made changes.
+func runPreCreateHooks(issues []Issue, run *Run) error {
+	if *preCreateHook == "" {
+		return nil
+	}
+	for _, issue := range issues {
+		if err := runHook(*preCreateHook, newHookIssue(issue), run, issue.Index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postCreateHook runs --post-create-hook for a created issue.
+func postCreateHook(tracker Tracker, run *Run, issue Issue, created CreatedIssue) error {
+	if *postCreateHookCommand == "" {
+		return nil
+	}
+
+	payload := hookCreated{hookIssue: newHookIssue(issue), Created: created}
+	return runHook(*postCreateHookCommand, payload, run, issue.Index)
+}
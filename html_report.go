@@ -0,0 +1,142 @@
+package main
+
+import (
+	htmltemplate "html/template"
+	"os"
+	"strings"
+)
+
+var (
+	reportHTMLPath = createCmd.Flag(
+		"report-html",
+		"Path to write a standalone HTML report of the run to, with the rendered issues, links to those created, any error and the timings.",
+	).String()
+)
+
+// rendered holds the issues rendered by the current run, for the reports
+// written once it ends.
+var rendered []Issue
+
+// htmlReportIssue is a rendered issue and what became of it.
+type htmlReportIssue struct {
+	Issue
+	Key  string
+	Link string
+}
+
+// phaseTime is how long a phase of the run took.
+type phaseTime struct {
+	Phase   string  `json:"phase"`
+	Seconds float64 `json:"seconds"`
+}
+
+// Phases returns how long each phase took, in the order they were started.
+func (t *phaseTimer) Phases() []phaseTime {
+	t.Stop()
+	phases := make([]phaseTime, 0, len(t.phases))
+	for _, phase := range t.phases {
+		phases = append(phases, phaseTime{Phase: phase, Seconds: t.elapsed[phase].Seconds()})
+	}
+	return phases
+}
+
+// issueLink returns a link to a created issue for people, if the backend
+// has one to give.
+func issueLink(key string) string {
+	if *backend == "jira" && *jiraURL != nil {
+		return strings.TrimSuffix((*jiraURL).String(), "/") + "/browse/" + key
+	}
+	return ""
+}
+
+const htmlReportTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>epic-creator run {{ .Run.ID }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 60em; color: #172b4d; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #dfe1e6; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+.error { background: #ffebe6; border: 1px solid #de350b; padding: 0.6em; white-space: pre-wrap; }
+.issue { border-top: 1px solid #dfe1e6; padding-top: 0.5em; }
+.missing { color: #97a0af; }
+pre { background: #f4f5f7; padding: 0.6em; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>epic-creator run {{ .Run.ID }}</h1>
+<table>
+<tr><th>Epic</th><td>{{ if .EpicLink }}<a href="{{ .EpicLink }}">{{ .Run.Epic }}</a>{{ else }}{{ .Run.Epic }}{{ end }}</td></tr>
+<tr><th>Backend</th><td>{{ .Run.Backend }}</td></tr>
+<tr><th>Started</th><td>{{ .Run.Started.Format "2006-01-02 15:04:05 MST" }}</td></tr>
+<tr><th>Finished</th><td>{{ .Run.Finished.Format "2006-01-02 15:04:05 MST" }}</td></tr>
+<tr><th>Created</th><td>{{ len .Run.Created }} of {{ len .Issues }} issue(s)</td></tr>
+{{ if .Run.Version }}<tr><th>Version</th><td>{{ .Run.Version }}</td></tr>
+{{ end }}</table>
+{{ if .Run.Error }}<h2>Error</h2>
+<div class="error">{{ .Run.Error }}</div>
+{{ end }}{{ if .Timings }}<h2>Timings</h2>
+<table>
+{{ range .Timings }}<tr><td>{{ .Phase }}</td><td>{{ printf "%.2fs" .Seconds }}</td></tr>
+{{ end }}<tr><th>total</th><th>{{ printf "%.2fs" .Total }}</th></tr>
+</table>
+{{ end }}<h2>Issues</h2>
+{{ range .Issues }}<div class="issue">
+<h3>{{ if .Link }}<a href="{{ .Link }}">{{ .Key }}</a>: {{ else if .Key }}{{ .Key }}: {{ else }}<span class="missing">not created</span>: {{ end }}{{ .Summary }}</h3>
+<pre>{{ .Description }}</pre>
+</div>
+{{ end }}</body>
+</html>
+`
+
+// writeHTMLReport writes a standalone report of the run, with its styles
+// inline so the file can be attached or mailed on its own.
+func writeHTMLReport(p string, epic *Epic, run *Run) error {
+	tmpl, err := htmltemplate.New("report").Parse(htmlReportTemplate)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[int]string, len(run.Created))
+	for _, created := range run.Created {
+		keys[created.Ticket] = created.Key
+	}
+	issues := make([]htmlReportIssue, 0, len(rendered))
+	for _, issue := range rendered {
+		key := keys[issue.Index]
+		link := ""
+		if key != "" {
+			link = issueLink(key)
+		}
+		issues = append(issues, htmlReportIssue{Issue: issue, Key: key, Link: link})
+	}
+	epicURL := ""
+	if epic.Key != "" {
+		epicURL = epicLink(epic)
+	}
+	phases := timings.Phases()
+	var total float64
+	for _, phase := range phases {
+		total += phase.Seconds
+	}
+
+	f, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return tmpl.Execute(f, struct {
+		Run      *Run
+		EpicLink string
+		Issues   []htmlReportIssue
+		Timings  []phaseTime
+		Total    float64
+	}{
+		Run:      run,
+		EpicLink: epicURL,
+		Issues:   issues,
+		Timings:  phases,
+		Total:    total,
+	})
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// decodeTickets reads either a JSON list of tickets or newline-delimited
+// JSON, with one ticket per line. Each ticket is checked against the schema
+// as it's decoded, but all of them are returned together: a run needs the
+// whole set before it creates anything.
+func decodeTickets(r io.Reader) ([]Ticket, error) {
+	br := bufio.NewReader(r)
+	list := false
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			return []Ticket{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !unicode.IsSpace(rune(b)) {
+			list = b == '['
+			br.UnreadByte()
+			break
+		}
+	}
+
+	tickets := make([]Ticket, 0)
+	dec := json.NewDecoder(br)
+	if list {
+		// Consume the opening bracket.
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+	for {
+		if list && !dec.More() {
+			// Consume the closing bracket.
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			if delim, ok := tok.(json.Delim); !ok || delim != ']' {
+				return nil, fmt.Errorf("ticket %d: expected ], got %v", len(tickets), tok)
+			}
+			return tickets, nil
+		}
+
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if err == io.EOF && !list {
+			return tickets, nil
+		}
+		if err == nil {
+			err = validateTicket(raw)
+		}
+		var ticket Ticket
+		if err == nil {
+			err = json.Unmarshal(raw, &ticket)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ticket %d: %v", len(tickets), err)
+		}
+		tickets = append(tickets, ticket)
+	}
+}
+
+// loadTicketFile reads a tickets file, replacing each include with the
+// tickets of the files it names, in order. Include patterns, attachments and
+// params files are resolved relative to the file they appear in. including
+// holds the files being read further up, to catch include cycles.
+func loadTicketFile(p string, including []string) ([]Ticket, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return nil, err
+	}
+	for _, parent := range including {
+		if parent == abs {
+			return nil, fmt.Errorf("%s includes itself", p)
+		}
+	}
+	including = append(including, abs)
+
+	var entries []Ticket
+	if strings.ToLower(filepath.Ext(p)) == ".csv" {
+		entries, err = csvTickets(p, *csvMappingPath)
+		if err != nil {
+			return nil, err
+		}
+	} else if cmd := evaluatedTicketFile(p); cmd != nil {
+		out, err := evaluateTicketFile(cmd, p)
+		if err != nil {
+			return nil, err
+		}
+		entries, err = decodeTickets(out)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", p, err)
+		}
+	} else {
+		r := io.Reader(os.Stdin)
+		if p != "-" {
+			f, err := os.Open(p)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			r = f
+		}
+		entries, err = decodeTickets(r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", p, err)
+		}
+	}
+
+	dir := filepath.Dir(abs)
+	tickets := make([]Ticket, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Include == "" {
+			for i, attachment := range entry.Attachments {
+				if !filepath.IsAbs(attachment) {
+					entry.Attachments[i] = filepath.Join(dir, attachment)
+				}
+			}
+			if err := mergeParamsFile(&entry, dir); err != nil {
+				return nil, fmt.Errorf("%s: %v", p, err)
+			}
+			tickets = append(tickets, entry)
+			continue
+		}
+
+		pattern := entry.Include
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%s: include %q matched no files", p, entry.Include)
+		}
+		for _, match := range matches {
+			included, err := loadTicketFile(match, including)
+			if err != nil {
+				return nil, err
+			}
+			tickets = append(tickets, included...)
+		}
+	}
+	return tickets, nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeTickets(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		projects []string
+	}{
+		{"empty", "", []string{}},
+		{"empty list", "[]", []string{}},
+		{"empty list with space", " [ ]\n", []string{}},
+		{"list", `[{"project": "A"}, {"project": "B"}]`, []string{"A", "B"}},
+		{"indented list", "[\n  {\"project\": \"A\"},\n  {\"project\": \"B\"}\n]\n", []string{"A", "B"}},
+		{"ndjson", "{\"project\": \"A\"}\n{\"project\": \"B\"}\n", []string{"A", "B"}},
+		{"single object", `{"project": "A"}`, []string{"A"}},
+	}
+	for _, test := range tests {
+		tickets, err := decodeTickets(strings.NewReader(test.input))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if len(tickets) != len(test.projects) {
+			t.Errorf("%s: got %d ticket(s), want %d", test.name, len(tickets), len(test.projects))
+			continue
+		}
+		for i, ticket := range tickets {
+			if ticket.Project != test.projects[i] {
+				t.Errorf("%s: ticket %d has project %q, want %q", test.name, i, ticket.Project, test.projects[i])
+			}
+		}
+	}
+}
+
+func TestDecodeTicketsErrors(t *testing.T) {
+	for _, input := range []string{
+		`[{"project": "A"}`,
+		`[{"project": "A"},]`,
+		`[{"project": 1}]`,
+		`{"project": "A"} {"nope": true}`,
+	} {
+		if _, err := decodeTickets(strings.NewReader(input)); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
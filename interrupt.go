@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+)
+
+// stopSignals stop a run before the next issue is created, so that it ends
+// like any failed run: with its locks released and its record saved, audited
+// and reported.
+var stopSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+var errStopped = errors.New("stopped by a signal before every issue was created")
+
+// stopping is set once one of stopSignals is received.
+var stopping int32
+
+// stopOnSignal makes the first of stopSignals stop the run between issues.
+// A second one kills it at once, as it would have without.
+func stopOnSignal() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, stopSignals...)
+	go func() {
+		sig := <-c
+		signal.Reset(stopSignals...)
+		fmt.Fprintf(os.Stderr, "Got %v, stopping before the next issue. Send it again to stop at once.\n", sig)
+		atomic.StoreInt32(&stopping, 1)
+	}()
+}
+
+// checkStopped fails once the run has been asked to stop.
+func checkStopped() error {
+	if atomic.LoadInt32(&stopping) != 0 {
+		return errStopped
+	}
+	return nil
+}
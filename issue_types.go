@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+import (
+	jira "gopkg.in/andygrunwald/go-jira.v1"
+)
+
+// preferredIssueTypes are the issue types tickets are created as, in order
+// of preference, unless the project config names one.
+var preferredIssueTypes = []string{"Task", "Story"}
+
+// ticketIssueType picks the issue type to create tickets in the project as:
+// the issue_type from --project-config, else the first of
+// preferredIssueTypes the project has, else its first type which is
+// neither a sub-task nor an epic.
+func ticketIssueType(project *jira.Project) (jira.IssueType, error) {
+	if name := projectConfigFor(project.Key).IssueType; name != "" {
+		for _, issueType := range project.IssueTypes {
+			if strings.EqualFold(issueType.Name, name) {
+				return issueType, nil
+			}
+		}
+		return jira.IssueType{}, fmt.Errorf("project %s has no %s issue type, which --project-config names", project.Key, name)
+	}
+
+	for _, name := range preferredIssueTypes {
+		for _, issueType := range project.IssueTypes {
+			if !issueType.Subtask && strings.EqualFold(issueType.Name, name) {
+				return issueType, nil
+			}
+		}
+	}
+	names := make([]string, 0, len(project.IssueTypes))
+	for _, issueType := range project.IssueTypes {
+		if !issueType.Subtask && !strings.EqualFold(issueType.Name, epicType) {
+			return issueType, nil
+		}
+		names = append(names, issueType.Name)
+	}
+	if len(names) == 0 {
+		return jira.IssueType{}, fmt.Errorf("project %s has no issue types", project.Key)
+	}
+	return jira.IssueType{}, fmt.Errorf(
+		"project %s has no issue type to create tickets as, only %s; name one with issue_type in --project-config",
+		project.Key,
+		strings.Join(names, ", "),
+	)
+}
@@ -0,0 +1,477 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+import (
+	"github.com/trivago/tgo/tcontainer"
+	"gopkg.in/alecthomas/kingpin.v2"
+	jira "gopkg.in/andygrunwald/go-jira.v1"
+)
+
+func jiraAPIRequestErrorHandler(resp *jira.Response, err error) error {
+	if resp == nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Request: %v\n", resp.Response.Request)
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	fmt.Fprintf(os.Stderr, "Response body: %s\n", body)
+	if resp.StatusCode == http.StatusUnauthorized {
+		fmt.Fprintln(os.Stderr, authHint())
+	}
+	return err
+}
+
+// pendingIssue is a ticket which has been rendered into an issue, but not
+// yet created.
+type pendingIssue struct {
+	index  int
+	ticket Ticket
+	issue  jira.Issue
+	// adf is the description as an ADF document, when submitting through
+	// the v3 API.
+	adf *adfNode
+}
+
+// jiraIssueAPI is the base path of the issue API: v3 when submitting ADF
+// descriptions, v2 otherwise.
+func jiraIssueAPI() string {
+	if useADF() {
+		return "rest/api/3/issue"
+	}
+	return "rest/api/2/issue"
+}
+
+// payload returns the body with which to create the issue. go-jira only
+// knows descriptions as strings, so an ADF description is spliced into the
+// marshalled fields.
+func (p pendingIssue) payload() (interface{}, error) {
+	if p.adf == nil {
+		return &p.issue, nil
+	}
+
+	data, err := json.Marshal(&p.issue)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload struct {
+		Fields map[string]interface{} `json:"fields"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+	payload.Fields["description"] = p.adf
+	return &payload, nil
+}
+
+// jiraLinkTypes maps the kinds of link known to Tracker onto JIRA's default
+// issue link types.
+var jiraLinkTypes = map[string]string{
+	linkRelates: "Relates",
+	linkBlocks:  "Blocks",
+}
+
+func init() {
+	registerTracker("jira", newJIRATracker)
+}
+
+var (
+	storyPointsFieldID = kingpin.Flag(
+		"story-points-field",
+		"ID of the JIRA custom field holding story points. Detected from the field list if not given.",
+	).String()
+)
+
+type jiraTracker struct {
+	client      *jiraClient
+	pointsField string
+
+	// mu guards the metadata below, which Prefetch fetches concurrently.
+	mu       sync.Mutex
+	fields   []jiraField
+	projects map[string]*jira.Project
+	// users caches the users looked up by email address or display name.
+	users map[string]string
+	// teams caches the IDs of teams looked up by name.
+	teams map[string]string
+	// createMeta caches the fields each project and issue type require.
+	createMeta map[string][]createMetaField
+}
+
+func newJIRATracker() Tracker {
+	client := newJIRAClient(jiraTransport)
+	if err := detectJIRADeployment(client); err != nil {
+		panic(err)
+	}
+	return newJIRATrackerWithClient(client)
+}
+
+// newJIRATrackerWithClient returns a JIRA backend which uses client, which
+// may be a fake.
+func newJIRATrackerWithClient(client *jiraClient) *jiraTracker {
+	return &jiraTracker{client: client}
+}
+
+// ResolveEpic looks up the epic by its key, in any case. Epics in a done
+// status are closed.
+func (t *jiraTracker) ResolveEpic(name string) (*Epic, error) {
+	issue, err := getEpic(t.client, name)
+	if err != nil {
+		return nil, err
+	}
+
+	epic := &Epic{
+		ID:      issue.ID,
+		Key:     issue.Key,
+		URL:     issue.Self,
+		Project: issue.Fields.Project.Key,
+	}
+	if status := issue.Fields.Status; status != nil {
+		epic.Status = status.Name
+		epic.Closed = status.StatusCategory.Key == "done"
+	}
+	if err := t.fillEpicDetails(epic); err != nil {
+		return nil, err
+	}
+	return epic, nil
+}
+
+// fillEpicDetails fetches the epic's details for templates, naming its
+// custom fields.
+func (t *jiraTracker) fillEpicDetails(epic *Epic) error {
+	req, err := t.client.NewRequest("GET", "rest/api/2/issue/"+epic.Key+"?fields=*all", nil)
+	if err != nil {
+		return err
+	}
+	var issue struct {
+		Fields map[string]interface{} `json:"fields"`
+	}
+	resp, err := t.client.Do(req, &issue)
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+
+	epic.Summary, _ = issue.Fields["summary"].(string)
+	epic.Description, _ = issue.Fields["description"].(string)
+	epic.DueDate, _ = issue.Fields["duedate"].(string)
+	labels, _ := issue.Fields["labels"].([]interface{})
+	for _, label := range labels {
+		if s, ok := label.(string); ok {
+			epic.Labels = append(epic.Labels, s)
+		}
+	}
+
+	fields, err := t.allFields()
+	if err != nil {
+		return err
+	}
+	names := make(map[string]string, len(fields))
+	for _, field := range fields {
+		names[field.ID] = field.Name
+	}
+	epic.Fields = make(map[string]interface{})
+	for id, value := range issue.Fields {
+		if name, ok := names[id]; ok && value != nil && strings.HasPrefix(id, "customfield_") {
+			epic.Fields[name] = value
+		}
+	}
+	return nil
+}
+
+func (t *jiraTracker) CreateIssues(epic *Epic, issues []Issue, run *Run) error {
+	jiraEpic, err := toJIRAEpic(epic)
+	if err != nil {
+		return err
+	}
+
+	pending := make([]pendingIssue, 0, len(issues))
+	for _, issue := range issues {
+		ticket := issue.Ticket
+
+		project, err := t.project(ticket.Project, false)
+		if err != nil {
+			return err
+		}
+		if *metadataCacheTTL > 0 && !hasComponents(project, ticket) {
+			// The project may have been cached before the components
+			// were added.
+			if project, err = t.project(ticket.Project, true); err != nil {
+				return err
+			}
+		}
+		components := len(project.Components)
+		if err := ensureComponents(t.client, project, ticket); err != nil {
+			return err
+		}
+		if len(project.Components) != components {
+			if err := saveCached("project-"+project.Key, project); err != nil {
+				return err
+			}
+		}
+		issueType, err := ticketIssueType(project)
+		if err != nil {
+			return fmt.Errorf("ticket %d: %v", issue.Index, err)
+		}
+
+		description := issue.Description
+		var adf *adfNode
+		if useADF() {
+			doc := descriptionToADF(description, *descriptionFormat)
+			adf = &doc
+		} else if *descriptionFormat == "markdown" {
+			description = markdownToWiki(description)
+		}
+
+		// create issue struct
+		fields := jira.IssueFields{
+			Summary:     issue.Summary,
+			Description: description,
+			Type:        issueType,
+			Project:     *project,
+			Unknowns:    tcontainer.MarshalMap{},
+		}
+		if ticket.Assignee != "" {
+			fields.Unknowns["assignee"] = jiraUser(ticket.Assignee)
+		}
+		if ticket.Reporter != "" {
+			fields.Unknowns["reporter"] = jiraUser(ticket.Reporter)
+		}
+		if ticket.SecurityLevel != "" {
+			fields.Unknowns["security"] = securityLevel(ticket.SecurityLevel)
+		}
+		if ticket.Team != "" {
+			if err := t.setTeam(ticket.Team, fields.Unknowns); err != nil {
+				return fmt.Errorf("ticket %d: %v", issue.Index, err)
+			}
+		}
+		if ticket.Priority != "" {
+			fields.Priority = &jira.Priority{Name: ticket.Priority}
+		}
+		if len(ticket.Labels) > 0 {
+			fields.Labels = ticket.Labels
+		}
+		for _, name := range ticket.Components {
+			fields.Components = append(fields.Components, &jira.Component{Name: name})
+		}
+		if ticket.DueDate != "" {
+			fields.Unknowns["duedate"] = ticket.DueDate
+		}
+		if ticket.StartDate != "" {
+			field, err := t.startDateField()
+			if err != nil {
+				return err
+			}
+			fields.Unknowns[field] = ticket.StartDate
+		}
+		if tracking := timeTracking(ticket); tracking != nil {
+			fields.Unknowns["timetracking"] = tracking
+		}
+		if ticket.StoryPoints != 0 {
+			field, err := t.storyPointsField()
+			if err != nil {
+				return err
+			}
+			fields.Unknowns[field] = ticket.StoryPoints
+		}
+		if err := t.setFields(issue.Fields, fields.Unknowns); err != nil {
+			return fmt.Errorf("ticket %d: %v", issue.Index, err)
+		}
+		if ticket.CustomEpicField != "" {
+			fields.Unknowns[ticket.CustomEpicField] = epic.Key
+		} else if jiraCloud {
+			fields.Parent = &jira.Parent{Key: epic.Key}
+		} else {
+			fields.Epic = jiraEpic
+		}
+		pending = append(pending, pendingIssue{
+			index:  issue.Index,
+			ticket: ticket,
+			issue:  jira.Issue{Fields: &fields},
+			adf:    adf,
+		})
+	}
+	return submitIssues(t.client, pending, run)
+}
+
+func (t *jiraTracker) LinkIssues(from string, to string, kind string) error {
+	linkType, ok := jiraLinkTypes[kind]
+	if !ok {
+		return fmt.Errorf("unknown link kind %q", kind)
+	}
+
+	// JIRA describes the inward issue with the link type's outward
+	// description, so "from blocks to" has from as the inward issue.
+	resp, err := t.client.Issue.AddLink(&jira.IssueLink{
+		Type:         jira.IssueLinkType{Name: linkType},
+		InwardIssue:  &jira.Issue{Key: from},
+		OutwardIssue: &jira.Issue{Key: to},
+	})
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+	return nil
+}
+
+func (t *jiraTracker) AttachFile(key string, filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, resp, err := t.client.Issue.PostAttachment(key, f, filepath.Base(filePath))
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+	return nil
+}
+
+func (t *jiraTracker) AddComment(key string, body string) error {
+	_, resp, err := t.client.Issue.AddComment(key, &jira.Comment{Body: body})
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+	return nil
+}
+
+// AddWatcher adds a watcher by username on Server and Data Center, or by
+// accountId on Cloud.
+func (t *jiraTracker) AddWatcher(key string, user string) error {
+	req, err := t.client.NewRequest("POST", "rest/api/2/issue/"+key+"/watchers", user)
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Do(req, nil)
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+	return nil
+}
+
+func (t *jiraTracker) GetDescription(key string) (string, error) {
+	issue, resp, err := t.client.Issue.Get(key, &jira.GetQueryOptions{Fields: "description"})
+	if err != nil {
+		return "", jiraAPIRequestErrorHandler(resp, err)
+	}
+	return issue.Fields.Description, nil
+}
+
+// SetDescription always goes through the v2 API, which takes wiki markup
+// even on Cloud.
+func (t *jiraTracker) SetDescription(key string, description string) error {
+	return t.updateIssue(key, map[string]interface{}{
+		"fields": map[string]string{"description": description},
+	})
+}
+
+func (t *jiraTracker) DeleteIssue(key string) error {
+	req, err := t.client.NewRequest("DELETE", "rest/api/2/issue/"+key, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Do(req, nil)
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+	return nil
+}
+
+func (t *jiraTracker) CloseIssue(key string, transition string) error {
+	return t.TransitionIssue(key, transition)
+}
+
+func (t *jiraTracker) TransitionIssue(key string, name string) error {
+	return transitionIssue(t.client, key, name)
+}
+
+func toJIRAEpic(epic *Epic) (*jira.Epic, error) {
+	id, err := strconv.Atoi(epic.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jira.Epic{
+		ID:   id,
+		Self: epic.URL,
+		Key:  epic.Key,
+	}, nil
+}
+
+// createIssue creates a single issue and records it in the run.
+func createIssue(client *jiraClient, p pendingIssue, run *Run) (*jira.Issue, error) {
+	payload, err := p.payload()
+	if err != nil {
+		return nil, err
+	}
+	req, err := client.NewRequest("POST", jiraIssueAPI(), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	createdIssue := new(jira.Issue)
+	resp, err := client.Do(req, createdIssue)
+	if err != nil {
+		return nil, jiraAPIRequestErrorHandler(resp, err)
+	}
+	printCreated(createdIssue.Key, p.issue.Fields.Summary, issueLink(createdIssue.Key))
+
+	err = run.Record(CreatedIssue{
+		ID:      createdIssue.ID,
+		Key:     createdIssue.Key,
+		Project: p.ticket.Project,
+		Summary: p.issue.Fields.Summary,
+		Ticket:  p.index,
+	})
+	return createdIssue, err
+}
+
+func transitionIssue(client *jiraClient, key string, name string) error {
+	transitions, resp, err := client.Issue.GetTransitions(key)
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+
+	for _, transition := range transitions {
+		if strings.EqualFold(transition.Name, name) {
+			resp, err = client.Issue.DoTransition(key, transition.ID)
+			if err != nil {
+				return jiraAPIRequestErrorHandler(resp, err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("no transition named %q available for %s", name, key)
+}
+
+// getEpic fetches the issue named by epicName, failing with a notEpicError
+// if it isn't an epic.
+func getEpic(client *jiraClient, epicName string) (*jira.Issue, error) {
+	issue, resp, err := client.Issue.Get(
+		strings.ToUpper(strings.TrimSpace(epicName)),
+		&jira.GetQueryOptions{
+			Fields: "issuetype,status,project",
+		},
+	)
+
+	if err != nil {
+		return nil, jiraAPIRequestErrorHandler(resp, err)
+	}
+
+	if !strings.EqualFold(issue.Fields.Type.Name, "Epic") {
+		return nil, &notEpicError{Key: issue.Key, Type: issue.Fields.Type.Name}
+	}
+	return issue, nil
+}
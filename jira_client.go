@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+import (
+	jira "gopkg.in/andygrunwald/go-jira.v1"
+)
+
+// The parts of go-jira epic-creator uses, so that the JIRA backend can be
+// run against fakes.
+
+// jiraRequester makes raw requests against the JIRA API.
+type jiraRequester interface {
+	NewRequest(method, urlStr string, body interface{}) (*http.Request, error)
+	Do(req *http.Request, v interface{}) (*jira.Response, error)
+}
+
+// jiraIssueService is the subset of go-jira's IssueService in use.
+type jiraIssueService interface {
+	Get(issueID string, options *jira.GetQueryOptions) (*jira.Issue, *jira.Response, error)
+	AddComment(issueID string, comment *jira.Comment) (*jira.Comment, *jira.Response, error)
+	AddLink(issueLink *jira.IssueLink) (*jira.Response, error)
+	PostAttachment(issueID string, r io.Reader, attachmentName string) (*[]jira.Attachment, *jira.Response, error)
+	GetTransitions(id string) ([]jira.Transition, *jira.Response, error)
+	DoTransition(ticketID, transitionID string) (*jira.Response, error)
+}
+
+// jiraProjectService is the subset of go-jira's ProjectService in use.
+type jiraProjectService interface {
+	Get(projectID string) (*jira.Project, *jira.Response, error)
+}
+
+// jiraClient has the same shape as a *jira.Client, but over interfaces.
+type jiraClient struct {
+	jiraRequester
+	Issue   jiraIssueService
+	Project jiraProjectService
+}
+
+// jiraTransport is the transport JIRA requests are made through, or nil for
+// http.DefaultTransport.
+var jiraTransport http.RoundTripper
+
+func wrapJIRAClient(client *jira.Client) *jiraClient {
+	return &jiraClient{
+		jiraRequester: client,
+		Issue:         client.Issue,
+		Project:       client.Project,
+	}
+}
+
+// jiraMyself returns the username of the user the client is logged in as.
+func jiraMyself(client *jiraClient) (string, error) {
+	req, err := client.NewRequest("GET", "rest/api/2/myself", nil)
+	if err != nil {
+		return "", err
+	}
+	var myself struct {
+		Name string `json:"name"`
+	}
+	resp, err := client.Do(req, &myself)
+	if err != nil {
+		return "", jiraAPIRequestErrorHandler(resp, err)
+	}
+	return myself.Name, nil
+}
+
+// newJIRAClient returns a client for --jira-url, which makes its requests
+// through transport. It's authenticated with the credentials in the auth
+// file, sent with each request or, for --jira-auth session, once for a
+// session cookie, or with Kerberos for --jira-auth negotiate.
+func newJIRAClient(transport http.RoundTripper) *jiraClient {
+	if *jiraAuth == "negotiate" {
+		httpClient, err := negotiateClient(transport)
+		if err != nil {
+			panic(err)
+		}
+		client, err := jira.NewClient(httpClient, (*jiraURL).String())
+		if err != nil {
+			panic(err)
+		}
+		wrapped := wrapJIRAClient(client)
+		// There are no credentials to say who's logged in, so JIRA's
+		// asked, for the audit log.
+		if trackerLogin, err = jiraMyself(wrapped); err != nil {
+			panic(fmt.Errorf("logging into JIRA with Kerberos: %v", err))
+		}
+		return wrapped
+	}
+
+	creds, err := trackerCreds()
+	if os.IsNotExist(err) && isMockJIRA(transport) {
+		// The mock server doesn't check credentials, so CI can run
+		// against it without any.
+		client, err := jira.NewClient(&http.Client{Transport: transport}, (*jiraURL).String())
+		if err != nil {
+			panic(err)
+		}
+		return wrapJIRAClient(client)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	client, err := jira.NewClient(&http.Client{Transport: transport}, (*jiraURL).String())
+	if err != nil {
+		panic(err)
+	}
+	if *jiraAuth == "session" {
+		// For older Server instances, where a WAF blocks basic auth on
+		// every request.
+		if ok, err := client.Authentication.AcquireSessionCookie(creds.User, creds.Password); !ok {
+			panic(fmt.Errorf("logging into a JIRA session as %s: %v", creds.User, err))
+		}
+		return wrapJIRAClient(client)
+	}
+	client.Authentication.SetBasicAuth(creds.User, creds.Password)
+	return wrapJIRAClient(client)
+}
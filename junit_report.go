@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+)
+
+var (
+	reportJUnitPath = createCmd.Flag(
+		"report-junit",
+		"Path to write a JUnit XML report of the run to, with a test case per ticket which fails if the ticket's issue wasn't created.",
+	).String()
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// writeJUnitReport writes the run as a JUnit test suite, so that CI shows
+// which tickets failed. A ticket passes if its issue was created. Errors
+// aren't tied to a ticket, so if the run failed every ticket not created
+// fails with the run's error; if it succeeded they were left out on purpose,
+// such as when watching, and are skipped.
+func writeJUnitReport(p string, run *Run) error {
+	keys := make(map[int]string, len(run.Created))
+	for _, created := range run.Created {
+		keys[created.Ticket] = created.Key
+	}
+
+	suite := junitTestSuite{
+		Name:  "epic-creator " + run.ID,
+		Tests: len(rendered),
+		Time:  fmt.Sprintf("%.3f", run.Finished.Sub(run.Started).Seconds()),
+		Cases: make([]junitTestCase, 0, len(rendered)),
+	}
+	for _, issue := range rendered {
+		tc := junitTestCase{
+			Name:      fmt.Sprintf("ticket %d: %s", issue.Index, issue.Summary),
+			ClassName: run.Epic,
+		}
+		if issue.Epic != nil {
+			tc.ClassName = issue.Epic.Key
+		}
+		if key, ok := keys[issue.Index]; ok {
+			tc.SystemOut = "Created " + key
+		} else if run.Error != "" {
+			tc.Failure = &junitFailure{Message: run.Error, Text: run.Error}
+			suite.Failures++
+		} else {
+			tc.Skipped = &junitSkipped{Message: "not created"}
+			suite.Skipped++
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p, append([]byte(xml.Header), append(data, '\n')...), 0644)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+)
+
+var (
+	runLabels = createCmd.Flag(
+		"label",
+		"Label to add to every issue, on top of the ticket's own. May be repeated.",
+	).Strings()
+	runComponents = createCmd.Flag(
+		"component",
+		"Component to add every issue to, on top of the ticket's own. May be repeated.",
+	).Strings()
+	runPriority = createCmd.Flag(
+		"priority",
+		"Priority of issues whose ticket doesn't give one.",
+	).String()
+	runLabel = createCmd.Flag(
+		"run-label",
+		"Label every issue with the run's ID, so the run's issues can be found together.",
+	).Bool()
+)
+
+// applyRunFields adds the labels and components given with --label and
+// --component to the ticket, and sets --priority if it has none.
+func applyRunFields(ticket *Ticket) {
+	ticket.Labels = mergeNames(ticket.Labels, *runLabels)
+	ticket.Components = mergeNames(ticket.Components, *runComponents)
+	if ticket.Priority == "" {
+		ticket.Priority = *runPriority
+	}
+}
+
+// runLabelName is the label --run-label adds to the run's issues. Labels
+// can't have spaces, and run IDs don't.
+func runLabelName(run *Run) string {
+	return "epic-creator-" + strings.ToLower(run.ID)
+}
+
+// mergeNames appends the names in more which aren't already in names,
+// ignoring case.
+func mergeNames(names []string, more []string) []string {
+	for _, name := range more {
+		found := false
+		for _, existing := range names {
+			if strings.EqualFold(existing, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jiraSummaryLimit is the most characters JIRA allows in a summary.
+const jiraSummaryLimit = 255
+
+var (
+	lengthPolicy = createCmd.Flag(
+		"length-policy",
+		"What to do with summaries and descriptions over their limits: fail before creating anything, truncate them with an ellipsis, or truncate them and move the overflow into a comment.",
+	).Default("fail").Enum("fail", "truncate", "comment")
+	descriptionLimit = createCmd.Flag(
+		"description-limit",
+		"Most characters the instance allows in a description. JIRA's default is 32767.",
+	).Default("32767").Int()
+)
+
+// ellipsisMark is what truncated text ends with: an ellipsis, or three dots
+// with --sanitize.
+func ellipsisMark() string {
+	if *sanitize {
+		return "..."
+	}
+	return "…"
+}
+
+// truncate cuts s to limit characters, ending with an ellipsis, returning the
+// cut text and what was cut off it.
+func truncate(s string, limit int) (string, string) {
+	ellipsis := ellipsisMark()
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s, ""
+	}
+	cut := limit - len([]rune(ellipsis))
+	if cut < 0 {
+		cut = 0
+	}
+	return string(runes[:cut]) + ellipsis, string(runes[cut:])
+}
+
+// textLength is how many characters s is.
+func textLength(s string) int {
+	return len([]rune(s))
+}
+
+// submittedDescriptionLength is how many characters a rendered description
+// is once converted for submission: JIRA is sent Markdown as wiki markup, and
+// Cloud is sent an ADF document, whose JSON is what its limit counts.
+func submittedDescriptionLength(description string) int {
+	if *backend != "jira" {
+		return textLength(description)
+	}
+	if useADF() {
+		data, err := json.Marshal(descriptionToADF(description, *descriptionFormat))
+		if err != nil {
+			return textLength(description)
+		}
+		return textLength(string(data))
+	}
+	if *descriptionFormat == "markdown" {
+		return textLength(markdownToWiki(description))
+	}
+	return textLength(description)
+}
+
+// applyLengthPolicy checks the issue's summary and description, as they'll
+// be submitted, against their limits, failing or truncating them as
+// --length-policy says.
+func applyLengthPolicy(issue *Issue) error {
+	limits := []struct {
+		name   string
+		text   *string
+		length func(string) int
+		limit  int
+	}{
+		{"summary", &issue.Summary, textLength, jiraSummaryLimit},
+		{"description", &issue.Description, submittedDescriptionLength, *descriptionLimit},
+	}
+
+	overflow := make([]string, 0)
+	for _, l := range limits {
+		n := l.length(*l.text)
+		if n <= l.limit {
+			continue
+		}
+		if *lengthPolicy == "fail" {
+			return fmt.Errorf("%s is %d characters, over the limit of %d", l.name, n, l.limit)
+		}
+
+		// Conversion can make the text longer, so cut it until what's
+		// submitted fits.
+		keep := l.limit
+		cut, rest := truncate(*l.text, keep)
+		for keep > 0 && l.length(cut) > l.limit {
+			keep -= l.length(cut) - l.limit
+			if keep < 0 {
+				keep = 0
+			}
+			cut, rest = truncate(*l.text, keep)
+		}
+		*l.text = cut
+		fmt.Printf("Truncated the %s of ticket %d from %d to %d characters.\n", l.name, issue.Index, n, l.length(cut))
+		overflow = append(overflow, fmt.Sprintf("%s (continued): %s%s", strings.Title(l.name), ellipsisMark(), rest))
+	}
+
+	if *lengthPolicy == "comment" && len(overflow) > 0 {
+		if issue.Comment != "" {
+			overflow = append([]string{issue.Comment}, overflow...)
+		}
+		issue.Comment = strings.Join(overflow, "\n\n")
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyLengthPolicy(t *testing.T) {
+	*backend = "jira"
+	*jiraADF, jiraCloud = false, false
+	*sanitize = false
+	*descriptionFormat = "wiki"
+	*descriptionLimit = 10
+
+	long := strings.Repeat("a", jiraSummaryLimit+5)
+	tests := []struct {
+		name        string
+		policy      string
+		issue       Issue
+		wantErr     bool
+		summary     string
+		description string
+		comment     string
+	}{
+		{
+			name:        "within limits",
+			policy:      "fail",
+			issue:       Issue{Summary: "Short", Description: "0123456789"},
+			summary:     "Short",
+			description: "0123456789",
+		},
+		{
+			name:    "fail on summary",
+			policy:  "fail",
+			issue:   Issue{Summary: long},
+			wantErr: true,
+		},
+		{
+			name:    "fail on description",
+			policy:  "fail",
+			issue:   Issue{Summary: "Short", Description: "0123456789abc"},
+			wantErr: true,
+		},
+		{
+			name:        "truncate",
+			policy:      "truncate",
+			issue:       Issue{Summary: long, Description: "0123456789abc"},
+			summary:     long[:jiraSummaryLimit-1] + "…",
+			description: "012345678…",
+		},
+		{
+			name:        "comment",
+			policy:      "comment",
+			issue:       Issue{Summary: "Short", Description: "0123456789abc", Comment: "First"},
+			summary:     "Short",
+			description: "012345678…",
+			comment:     "First\n\nDescription (continued): …9abc",
+		},
+	}
+	for _, test := range tests {
+		*lengthPolicy = test.policy
+		issue := test.issue
+		err := applyLengthPolicy(&issue)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if issue.Summary != test.summary {
+			t.Errorf("%s: got summary %q, want %q", test.name, issue.Summary, test.summary)
+		}
+		if issue.Description != test.description {
+			t.Errorf("%s: got description %q, want %q", test.name, issue.Description, test.description)
+		}
+		if issue.Comment != test.comment {
+			t.Errorf("%s: got comment %q, want %q", test.name, issue.Comment, test.comment)
+		}
+	}
+}
+
+func TestApplyLengthPolicyConvertedMarkdown(t *testing.T) {
+	*backend = "jira"
+	*jiraADF, jiraCloud = false, false
+	*sanitize = false
+	*descriptionFormat = "markdown"
+	*descriptionLimit = 50
+	*lengthPolicy = "truncate"
+
+	// Each `x` becomes {{x}} in wiki markup, so the description is under the
+	// limit as written but over it once converted.
+	description := strings.Repeat("`x` ", 12)
+	if textLength(description) > *descriptionLimit {
+		t.Fatalf("description is %d characters, want it under the limit", textLength(description))
+	}
+	issue := Issue{Summary: "Short", Description: description}
+	if err := applyLengthPolicy(&issue); err != nil {
+		t.Fatal(err)
+	}
+	if issue.Description == description {
+		t.Errorf("description wasn't truncated, though it's %d characters converted", submittedDescriptionLength(description))
+	}
+	if n := submittedDescriptionLength(issue.Description); n > *descriptionLimit {
+		t.Errorf("truncated description is %d characters converted, over the limit of %d", n, *descriptionLimit)
+	}
+}
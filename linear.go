@@ -0,0 +1,384 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	linearURL = kingpin.Flag(
+		"linear-url",
+		"Linear GraphQL API URL, for the linear backend.",
+	).Default("https://api.linear.app/graphql").String()
+)
+
+// linearTracker creates issues in Linear. Tickets name their team by its key
+// (e.g. "ENG") in the project field. The epic is a Linear project ID,
+// optionally followed by "/" and the name of one of its milestones.
+type linearTracker struct {
+	*restClient
+	teams  map[string]string
+	users  map[string]string
+	labels map[string]string
+}
+
+type linearError struct {
+	Message string `json:"message"`
+}
+
+type linearIssue struct {
+	ID         string `json:"id"`
+	Identifier string `json:"identifier"`
+	URL        string `json:"url"`
+	Team       struct {
+		States struct {
+			Nodes []struct {
+				ID string `json:"id"`
+			} `json:"nodes"`
+		} `json:"states"`
+	} `json:"team"`
+}
+
+// linearLinkTypes maps the kinds of link known to Tracker onto Linear's
+// issue relation types.
+var linearLinkTypes = map[string]string{
+	linkRelates: "related",
+	linkBlocks:  "blocks",
+}
+
+func init() {
+	registerTracker("linear", newLinearTracker)
+}
+
+func newLinearTracker() Tracker {
+	creds, err := trackerCreds()
+	if err != nil {
+		panic(err)
+	}
+
+	header := make(http.Header)
+	header.Set("Authorization", creds.Password)
+	return &linearTracker{
+		restClient: newRESTClient(*linearURL, header),
+		teams:      make(map[string]string),
+		users:      make(map[string]string),
+		labels:     make(map[string]string),
+	}
+}
+
+// query runs a GraphQL query and decodes its data into v.
+func (t *linearTracker) query(query string, variables map[string]interface{}, v interface{}) error {
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []linearError   `json:"errors"`
+	}
+	body := map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	}
+	if err := t.do("POST", "", body, &result); err != nil {
+		return err
+	}
+
+	if len(result.Errors) > 0 {
+		messages := make([]string, len(result.Errors))
+		for i, e := range result.Errors {
+			messages[i] = e.Message
+		}
+		return fmt.Errorf("linear: %s", strings.Join(messages, "; "))
+	}
+	return json.Unmarshal(result.Data, v)
+}
+
+// splitLinearEpic splits an epic of the form "<project-id>[/<milestone>]".
+func splitLinearEpic(name string) (string, string) {
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+func (t *linearTracker) ResolveEpic(name string) (*Epic, error) {
+	projectID, milestoneName := splitLinearEpic(name)
+
+	var result struct {
+		Project struct {
+			ID         string `json:"id"`
+			URL        string `json:"url"`
+			State      string `json:"state"`
+			Milestones struct {
+				Nodes []struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+				} `json:"nodes"`
+			} `json:"projectMilestones"`
+		} `json:"project"`
+	}
+	err := t.query(
+		`query($id: String!) { project(id: $id) { id url state projectMilestones { nodes { id name } } } }`,
+		map[string]interface{}{"id": projectID},
+		&result,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	state := result.Project.State
+	epic := &Epic{
+		Key:    result.Project.ID,
+		URL:    result.Project.URL,
+		Status: state,
+		Closed: state == "completed" || state == "canceled",
+	}
+	if milestoneName == "" {
+		return epic, nil
+	}
+	for _, milestone := range result.Project.Milestones.Nodes {
+		if milestone.Name == milestoneName {
+			epic.ID = milestone.ID
+			return epic, nil
+		}
+	}
+	return nil, fmt.Errorf("project %s has no milestone named %q", projectID, milestoneName)
+}
+
+func (t *linearTracker) teamID(key string) (string, error) {
+	if id, ok := t.teams[key]; ok {
+		return id, nil
+	}
+
+	var result struct {
+		Teams struct {
+			Nodes []struct {
+				ID string `json:"id"`
+			} `json:"nodes"`
+		} `json:"teams"`
+	}
+	err := t.query(
+		`query($key: String!) { teams(filter: { key: { eq: $key } }) { nodes { id } } }`,
+		map[string]interface{}{"key": key},
+		&result,
+	)
+	if err != nil {
+		return "", err
+	}
+	if len(result.Teams.Nodes) == 0 {
+		return "", fmt.Errorf("no Linear team with key %q", key)
+	}
+
+	t.teams[key] = result.Teams.Nodes[0].ID
+	return t.teams[key], nil
+}
+
+// userID looks up a Linear user by email address.
+func (t *linearTracker) userID(email string) (string, error) {
+	if id, ok := t.users[email]; ok {
+		return id, nil
+	}
+
+	var result struct {
+		Users struct {
+			Nodes []struct {
+				ID string `json:"id"`
+			} `json:"nodes"`
+		} `json:"users"`
+	}
+	err := t.query(
+		`query($email: String!) { users(filter: { email: { eq: $email } }) { nodes { id } } }`,
+		map[string]interface{}{"email": email},
+		&result,
+	)
+	if err != nil {
+		return "", err
+	}
+	if len(result.Users.Nodes) == 0 {
+		return "", fmt.Errorf("no Linear user with email %q", email)
+	}
+
+	t.users[email] = result.Users.Nodes[0].ID
+	return t.users[email], nil
+}
+
+// labelID looks up a Linear issue label by name.
+func (t *linearTracker) labelID(name string) (string, error) {
+	if id, ok := t.labels[name]; ok {
+		return id, nil
+	}
+
+	var result struct {
+		IssueLabels struct {
+			Nodes []struct {
+				ID string `json:"id"`
+			} `json:"nodes"`
+		} `json:"issueLabels"`
+	}
+	err := t.query(
+		`query($name: String!) { issueLabels(filter: { name: { eq: $name } }) { nodes { id } } }`,
+		map[string]interface{}{"name": name},
+		&result,
+	)
+	if err != nil {
+		return "", err
+	}
+	if len(result.IssueLabels.Nodes) == 0 {
+		return "", fmt.Errorf("no Linear label named %q", name)
+	}
+
+	t.labels[name] = result.IssueLabels.Nodes[0].ID
+	return t.labels[name], nil
+}
+
+// CreateIssues creates the issues in the epic's project. The epic's ID is
+// the milestone, if one was named.
+func (t *linearTracker) CreateIssues(epic *Epic, issues []Issue, run *Run) error {
+	for _, issue := range issues {
+		if err := checkStopped(); err != nil {
+			return err
+		}
+		teamID, err := t.teamID(issue.Ticket.Project)
+		if err != nil {
+			return err
+		}
+
+		input := map[string]interface{}{
+			"teamId":      teamID,
+			"title":       issue.Summary,
+			"description": issue.Description,
+			"projectId":   epic.Key,
+		}
+		if epic.ID != "" {
+			input["projectMilestoneId"] = epic.ID
+		}
+		if issue.Ticket.DueDate != "" {
+			input["dueDate"] = issue.Ticket.DueDate
+		}
+		if issue.Ticket.Assignee != "" {
+			userID, err := t.userID(issue.Ticket.Assignee)
+			if err != nil {
+				return err
+			}
+			input["assigneeId"] = userID
+		}
+		if issue.Ticket.StoryPoints != 0 {
+			input["estimate"] = int(issue.Ticket.StoryPoints)
+		}
+		if labels := issue.Ticket.allLabels(); len(labels) > 0 {
+			labelIDs := make([]string, len(labels))
+			for i, name := range labels {
+				if labelIDs[i], err = t.labelID(name); err != nil {
+					return err
+				}
+			}
+			input["labelIds"] = labelIDs
+		}
+
+		var result struct {
+			IssueCreate struct {
+				Issue linearIssue `json:"issue"`
+			} `json:"issueCreate"`
+		}
+		err = t.query(
+			`mutation($input: IssueCreateInput!) { issueCreate(input: $input) { issue { id identifier url } } }`,
+			map[string]interface{}{"input": input},
+			&result,
+		)
+		if err != nil {
+			return err
+		}
+
+		created := result.IssueCreate.Issue
+		printCreated(created.Identifier, issue.Summary, created.URL)
+		err = run.Record(CreatedIssue{
+			ID:      created.ID,
+			Key:     created.Identifier,
+			Project: issue.Ticket.Project,
+			Summary: issue.Summary,
+			Ticket:  issue.Index,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *linearTracker) LinkIssues(from string, to string, kind string) error {
+	relation, ok := linearLinkTypes[kind]
+	if !ok {
+		return fmt.Errorf("unknown link kind %q", kind)
+	}
+
+	input := map[string]interface{}{
+		"issueId":        from,
+		"relatedIssueId": to,
+		"type":           relation,
+	}
+	var result json.RawMessage
+	return t.query(
+		`mutation($input: IssueRelationCreateInput!) { issueRelationCreate(input: $input) { success } }`,
+		map[string]interface{}{"input": input},
+		&result,
+	)
+}
+
+func (t *linearTracker) AddComment(key string, body string) error {
+	input := map[string]interface{}{
+		"issueId": key,
+		"body":    body,
+	}
+	var result json.RawMessage
+	return t.query(
+		`mutation($input: CommentCreateInput!) { commentCreate(input: $input) { success } }`,
+		map[string]interface{}{"input": input},
+		&result,
+	)
+}
+
+// DeleteIssue moves the issue to Linear's trash.
+func (t *linearTracker) DeleteIssue(key string) error {
+	var result json.RawMessage
+	return t.query(
+		`mutation($id: String!) { issueDelete(id: $id) { success } }`,
+		map[string]interface{}{"id": key},
+		&result,
+	)
+}
+
+// CloseIssue moves the issue to the workflow state named by transition.
+func (t *linearTracker) CloseIssue(key string, transition string) error {
+	return t.TransitionIssue(key, transition)
+}
+
+// TransitionIssue moves the issue to the named workflow state.
+func (t *linearTracker) TransitionIssue(key string, name string) error {
+	var result struct {
+		Issue linearIssue `json:"issue"`
+	}
+	err := t.query(
+		`query($id: String!, $name: String!) { issue(id: $id) { team { states(filter: { name: { eq: $name } }) { nodes { id } } } } }`,
+		map[string]interface{}{"id": key, "name": name},
+		&result,
+	)
+	if err != nil {
+		return err
+	}
+
+	states := result.Issue.Team.States.Nodes
+	if len(states) == 0 {
+		return fmt.Errorf("no workflow state named %q for %s", name, key)
+	}
+
+	var update json.RawMessage
+	return t.query(
+		`mutation($id: String!, $stateId: String!) { issueUpdate(id: $id, input: { stateId: $stateId }) { success } }`,
+		map[string]interface{}{"id": key, "stateId": states[0].ID},
+		&update,
+	)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path"
+	"strings"
+	"time"
+)
+
+// lockProperty is the issue property an epic is locked with, with
+// --lock-epic.
+const lockProperty = "epic-creator.lock"
+
+var (
+	force = createCmd.Flag(
+		"force",
+		"Take over the locks on the run's epics, if another run holds them. Only for locks left behind by runs which died.",
+	).Bool()
+	lockEpic = createCmd.Flag(
+		"lock-epic",
+		"Also lock each epic with an issue property, to stop runs on other machines from racing this one.",
+	).Bool()
+)
+
+// epicLock records which run holds the lock on an epic.
+type epicLock struct {
+	Run     string    `json:"run"`
+	User    string    `json:"user"`
+	Host    string    `json:"host"`
+	PID     int       `json:"pid"`
+	Started time.Time `json:"started"`
+}
+
+func (l epicLock) String() string {
+	return fmt.Sprintf("run %s of %s@%s (pid %d) since %s", l.Run, l.User, l.Host, l.PID, l.Started.Format(time.RFC3339))
+}
+
+// lockedError is returned when another run holds the lock on an epic.
+type lockedError struct {
+	Epic   string
+	Holder epicLock
+}
+
+func (e *lockedError) Error() string {
+	return fmt.Sprintf(
+		"%s is locked by %s; pass --force if that run is no longer going",
+		e.Epic,
+		e.Holder,
+	)
+}
+
+// epicLocks are the locks a run holds.
+type epicLocks struct {
+	tracker Tracker
+	run     string
+	files   []string
+	epics   []string
+}
+
+func newEpicLock(run *Run) epicLock {
+	lock := epicLock{Run: run.ID, PID: os.Getpid(), Started: run.Started}
+	if u, err := user.Current(); err == nil {
+		lock.User = u.Username
+	}
+	lock.Host, _ = os.Hostname()
+	return lock
+}
+
+// lockEpics locks each of the epics for the run: with a file in the state
+// directory, and with --lock-epic an issue property on the epic too. If any
+// of them is already locked, those locked so far are released.
+func lockEpics(tracker Tracker, run *Run, epics []string) (*epicLocks, error) {
+	dir := path.Join(*stateDir, "locks")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	locks := &epicLocks{tracker: tracker, run: run.ID}
+	lock := newEpicLock(run)
+	data, err := json.Marshal(&lock)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, epic := range epics {
+		// Keys like owner/repo#12 can't be file names, so the file is
+		// named after a hash of the key.
+		if err := locks.lockFile(path.Join(dir, cacheKey("epic", epic)+".lock"), epic, data); err != nil {
+			locks.Release()
+			return nil, err
+		}
+		if *lockEpic {
+			if err := locks.lockProperty(epic, lock); err != nil {
+				locks.Release()
+				return nil, err
+			}
+		}
+	}
+	return locks, nil
+}
+
+func (l *epicLocks) lockFile(p string, epic string, data []byte) error {
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		var holder epicLock
+		held, _ := ioutil.ReadFile(p)
+		json.Unmarshal(held, &holder)
+		// A lock held by this run was left behind before it was resumed.
+		if holder.Run != l.run {
+			if !*force {
+				return &lockedError{Epic: epic, Holder: holder}
+			}
+			fmt.Fprintf(os.Stderr, "Taking over the lock on %s from %s.\n", epic, holder)
+		}
+		f, err = os.Create(p)
+	}
+	if err != nil {
+		return err
+	}
+	l.files = append(l.files, p)
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (l *epicLocks) lockProperty(epic string, lock epicLock) error {
+	store, ok := l.tracker.(propertyStore)
+	if !ok {
+		return fmt.Errorf("backend %s does not support locking epics", *backend)
+	}
+
+	var holder epicLock
+	held, err := store.GetProperty(epic, lockProperty, &holder)
+	if err != nil {
+		return err
+	}
+	if held && holder.Run != l.run {
+		if !*force {
+			return &lockedError{Epic: epic, Holder: holder}
+		}
+		fmt.Fprintf(os.Stderr, "Taking over the lock on %s from %s.\n", epic, holder)
+	}
+	if err := store.SetProperty(epic, lockProperty, lock); err != nil {
+		return err
+	}
+	l.epics = append(l.epics, epic)
+	return nil
+}
+
+// Release removes the locks which the run still holds; one taken over by
+// another run with --force is left to that run. Failures are reported rather
+// than returned, so that they don't hide the run's own error.
+func (l *epicLocks) Release() {
+	for _, p := range l.files {
+		var holder epicLock
+		held, err := ioutil.ReadFile(p)
+		if err == nil {
+			err = json.Unmarshal(held, &holder)
+		}
+		if err == nil && holder.Run != l.run {
+			fmt.Fprintf(os.Stderr, "Not releasing the lock in %s, which was taken over by %s.\n", p, holder)
+			continue
+		}
+		if err := os.Remove(p); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to release lock: %v\n", err)
+		}
+	}
+	for _, epic := range l.epics {
+		store := l.tracker.(propertyStore)
+		var holder epicLock
+		held, err := store.GetProperty(epic, lockProperty, &holder)
+		if err == nil && held && holder.Run != l.run {
+			fmt.Fprintf(os.Stderr, "Not releasing the lock on %s, which was taken over by %s.\n", epic, holder)
+			continue
+		}
+		if err := store.DeleteProperty(epic, lockProperty); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to release lock on %s: %v\n", epic, err)
+		}
+	}
+}
+
+// runEpics are the epics a run creates issues in which already exist: the
+// one on the command line, and those tickets name which aren't created from
+// the hierarchy.
+func runEpics(epic *Epic, tickets []Ticket, h *hierarchy) []string {
+	seen := make(map[string]bool)
+	if h != nil {
+		for _, node := range h.Epics {
+			seen[strings.ToUpper(node.Name)] = true
+		}
+	}
+
+	epics := make([]string, 0)
+	add := func(name string) {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name != "" && !seen[name] {
+			seen[name] = true
+			epics = append(epics, name)
+		}
+	}
+	if epic != nil {
+		add(epic.Key)
+	}
+	for _, ticket := range tickets {
+		add(ticket.Epic)
+	}
+	return epics
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+import (
+	jira "gopkg.in/andygrunwald/go-jira.v1"
+)
+
+// LookedUpIssue is an issue as templates see it through jiraIssue.
+type LookedUpIssue struct {
+	Key      string
+	Summary  string
+	Status   string
+	Assignee string
+	URL      string
+}
+
+// LookedUpUser is a user as templates see them through jiraUser.
+type LookedUpUser struct {
+	// ID is the accountId on Cloud, and the username elsewhere.
+	ID          string
+	DisplayName string
+	Email       string
+}
+
+// looker is implemented by trackers which templates can look issues and
+// users up in. Lookups only read.
+type looker interface {
+	LookupIssue(key string) (*LookedUpIssue, error)
+	LookupUser(user string) (*LookedUpUser, error)
+}
+
+// lookupTracker is the tracker which the lookup functions query. newTracker
+// sets it.
+var lookupTracker Tracker
+
+// lookups caches what templates have looked up, as the same issue or user
+// tends to be looked up for every ticket.
+var lookups = struct {
+	sync.Mutex
+	found map[string]interface{}
+}{found: make(map[string]interface{})}
+
+// lookup returns what fetch finds, caching it under key.
+func lookup(fn string, key string, fetch func(l looker) (interface{}, error)) (interface{}, error) {
+	if lookupTracker == nil {
+		return nil, fmt.Errorf("%s: there's no tracker to look %q up in", fn, key)
+	}
+	l, ok := lookupTracker.(looker)
+	if !ok {
+		return nil, fmt.Errorf("%s: backend %s does not support lookups", fn, *backend)
+	}
+
+	lookups.Lock()
+	found, ok := lookups.found[fn+" "+key]
+	lookups.Unlock()
+	if ok {
+		return found, nil
+	}
+	found, err := fetch(l)
+	if err != nil {
+		return nil, fmt.Errorf("%s %q: %v", fn, key, err)
+	}
+	lookups.Lock()
+	lookups.found[fn+" "+key] = found
+	lookups.Unlock()
+	return found, nil
+}
+
+// lookupFuncs are template functions which look up live data, such as the
+// summary of a linked issue:
+//
+//	See {{ (jiraIssue "OPS-1").Summary }}, owned by {{ (jiraUser .Params.owner).DisplayName }}.
+var lookupFuncs = template.FuncMap{
+	"jiraIssue": func(key string) (*LookedUpIssue, error) {
+		found, err := lookup("jiraIssue", strings.ToUpper(key), func(l looker) (interface{}, error) {
+			return l.LookupIssue(key)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return found.(*LookedUpIssue), nil
+	},
+	"jiraUser": func(user string) (*LookedUpUser, error) {
+		found, err := lookup("jiraUser", user, func(l looker) (interface{}, error) {
+			return l.LookupUser(user)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return found.(*LookedUpUser), nil
+	},
+}
+
+// LookupIssue fetches the issue's summary, status and assignee.
+func (t *jiraTracker) LookupIssue(key string) (*LookedUpIssue, error) {
+	issue, resp, err := t.client.Issue.Get(
+		strings.ToUpper(strings.TrimSpace(key)),
+		&jira.GetQueryOptions{Fields: "summary,status,assignee"},
+	)
+	if err != nil {
+		return nil, jiraAPIRequestErrorHandler(resp, err)
+	}
+
+	found := &LookedUpIssue{
+		Key:     issue.Key,
+		Summary: issue.Fields.Summary,
+		URL:     strings.TrimRight((*jiraURL).String(), "/") + "/browse/" + issue.Key,
+	}
+	if issue.Fields.Status != nil {
+		found.Status = issue.Fields.Status.Name
+	}
+	if issue.Fields.Assignee != nil {
+		found.Assignee = issue.Fields.Assignee.DisplayName
+	}
+	return found, nil
+}
+
+// LookupUser finds a user by email address or display name, or fetches
+// them by username, or accountId on Cloud.
+func (t *jiraTracker) LookupUser(user string) (*LookedUpUser, error) {
+	var found jiraUserResult
+	if strings.ContainsAny(user, "@ ") {
+		var err error
+		if found, err = t.searchUser(user); err != nil {
+			return nil, err
+		}
+	} else {
+		query := url.Values{"username": {user}}
+		if jiraCloud {
+			query = url.Values{"accountId": {user}}
+		}
+		req, err := t.client.NewRequest("GET", "rest/api/2/user?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := t.client.Do(req, &found)
+		if err != nil {
+			return nil, jiraAPIRequestErrorHandler(resp, err)
+		}
+	}
+	return &LookedUpUser{ID: found.id(), DisplayName: found.DisplayName, Email: found.EmailAddress}, nil
+}
@@ -4,17 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path"
-	"strconv"
+	"path/filepath"
+	"strings"
 	"text/template"
 )
 
 import (
-	"github.com/trivago/tgo/tcontainer"
 	"gopkg.in/alecthomas/kingpin.v2"
-	jira "gopkg.in/andygrunwald/go-jira.v1"
 )
 
 const (
@@ -30,7 +28,12 @@ const (
 		...
 	]
 
-	where "params" will be passed as context to the
+	or newline-delimited JSON, with one ticket per line.
+	Pass - to read tickets from stdin. Every ticket is loaded
+	before any is created, so very large sets are best split
+	across runs, with --only or --limit.
+
+	"params" will be passed as context to the
 	issue-template (see below).
 	For more information about golang templating, see
 	the text/template package documentation at
@@ -38,137 +41,397 @@ const (
 `
 )
 
-func jiraAPIRequestErrorHandler(resp *jira.Response, err error) error {
-	fmt.Fprintf(os.Stderr, "Request: %v\n", resp.Response.Request)
-	defer resp.Body.Close()
-	body, _ := ioutil.ReadAll(resp.Body)
-	fmt.Fprintf(os.Stderr, "Response body: %s\n", body)
-	return err
+type Ticket struct {
+	Project         string                 `json:"project"`
+	Params          map[string]interface{} `json:"params"`
+	CustomEpicField string                 `json:"custom_epic_field,omitempty"`
+	// ParamsFile is a JSON, YAML, Jsonnet or CUE file of params, relative to
+	// the tickets file, under the ticket's own params.
+	ParamsFile string `json:"params_file,omitempty"`
+	// Attachments are paths of files to upload to the issue, relative to
+	// the tickets file.
+	Attachments []string `json:"attachments,omitempty"`
+	// Watchers are users to add as watchers of the issue.
+	Watchers []string `json:"watchers,omitempty"`
+	// RemoteLinks are pages elsewhere to link the issue to once it's
+	// created.
+	RemoteLinks []RemoteLink `json:"remote_links,omitempty"`
+	// Comment is a template for a comment to post on the issue once it's
+	// created. It has the same context as the summary and description.
+	Comment string `json:"comment,omitempty"`
+	// Transition is the name of a workflow transition to move the issue
+	// through once it's created, overriding --transition.
+	Transition string `json:"transition,omitempty"`
+	// StartDate is when work on the issue starts, as YYYY-MM-DD.
+	StartDate string `json:"start_date,omitempty"`
+	// DueDate is when the issue is due, as YYYY-MM-DD.
+	DueDate string `json:"due_date,omitempty"`
+	// Estimate is the original time estimate, in JIRA's duration format
+	// (e.g. "2d 4h").
+	Estimate string `json:"estimate,omitempty"`
+	// RemainingEstimate is the time estimate left, if it isn't the
+	// original estimate.
+	RemainingEstimate string `json:"remaining_estimate,omitempty"`
+	// Worklog is work to log on the issue once it's created.
+	Worklog *Worklog `json:"worklog,omitempty"`
+	// StoryPoints is the issue's story point estimate.
+	StoryPoints float64 `json:"story_points,omitempty"`
+	// Assignee is the user to assign the issue to. Tickets without one are
+	// assigned from --assignees or --roster, if given.
+	Assignee string `json:"assignee,omitempty"`
+	// Reporter is the user to file the issue on behalf of, for service
+	// accounts. Needs the Modify Reporter permission.
+	Reporter string `json:"reporter,omitempty"`
+	// SecurityLevel is the name or ID of the issue's security level.
+	SecurityLevel string `json:"security_level,omitempty"`
+	// Team is the name or ID of the Advanced Roadmaps team the issue
+	// belongs to.
+	Team string `json:"team,omitempty"`
+	// Priority is the name of the issue's priority.
+	Priority string `json:"priority,omitempty"`
+	// Labels are labels to add to the issue.
+	Labels []string `json:"labels,omitempty"`
+	// Components are the project components the issue belongs to. Trackers
+	// without components add them as labels.
+	Components []string `json:"components,omitempty"`
+	// Tags select the ticket with --tag and --skip-tag. They aren't added
+	// to the issue.
+	Tags []string `json:"tags,omitempty"`
+	// When is a template, with the same context as the summary and
+	// description, which skips the ticket if it renders "false", "0", "no"
+	// or nothing.
+	When string `json:"when,omitempty"`
+	// Epic is the epic to create the issue in, instead of the one given on
+	// the command line.
+	Epic string `json:"epic,omitempty"`
+	// Matrix expands the ticket into one ticket per combination of its
+	// values, which are merged into params.
+	Matrix map[string][]interface{} `json:"matrix,omitempty"`
+	// MatrixFrom adds to the matrix, by key, the list held in the named
+	// param.
+	MatrixFrom map[string]string `json:"matrix_from,omitempty"`
+	// Globals, on an entry of their own, are run-wide values available to
+	// every template as .Globals. The entry isn't a ticket.
+	Globals map[string]interface{} `json:"globals,omitempty"`
+	// ID names the ticket for the depends_on of other tickets.
+	ID string `json:"id,omitempty"`
+	// DependsOn are the ids of tickets which block this one. The ticket is
+	// created after them, and linked as blocked by them.
+	DependsOn []string `json:"depends_on,omitempty"`
+	// Include is a path or glob of other tickets files, relative to this
+	// one, whose tickets take the place of this entry.
+	Include string `json:"include,omitempty"`
+
+	// Index is the ticket's place among the tickets selected for the run,
+	// counting from 1, and Total is how many there are, for summaries such
+	// as "Step 3/12". They're set just before rendering.
+	Index int `json:"-"`
+	Total int `json:"-"`
+	// PrevKey is the key of the issue created from the ticket before this
+	// one, or empty for the first.
+	PrevKey string `json:"-"`
 }
 
-type Ticket struct {
-	Project         string
-	Params          map[string]interface{}
-	CustomEpicField string `json:"custom_epic_field,omitempty"`
+// allLabels is the ticket's labels followed by its components, for trackers
+// which have no components.
+func (t Ticket) allLabels() []string {
+	labels := make([]string, 0, len(t.Labels)+len(t.Components))
+	labels = append(labels, t.Labels...)
+	return append(labels, t.Components...)
 }
 
 func loadTickets(ticketsFilePath string) ([]Ticket, error) {
-	data, err := ioutil.ReadFile(ticketsFilePath)
+	tickets, err := loadTicketFile(ticketsFilePath, nil)
 	if err != nil {
 		return nil, err
 	}
+	return prepareTickets(tickets)
+}
 
-	tickets := make([]Ticket, 0)
-	err = json.Unmarshal(data, &tickets)
-	return tickets, err
+// prepareTickets takes out the globals, expands matrices and applies the
+// field mapping, for tickets however they were read.
+func prepareTickets(tickets []Ticket) ([]Ticket, error) {
+	tickets, err := extractGlobals(tickets)
+	if err != nil {
+		return nil, err
+	}
+	tickets, err = expandMatrix(tickets)
+	if err != nil {
+		return nil, err
+	}
+	return applyFieldMapping(tickets)
 }
 
 func loadTemplate(issueTemplate string) (*template.Template, error) {
-	return template.ParseFiles(issueTemplate)
+	return newTemplate(filepath.Base(issueTemplate)).ParseFiles(issueTemplate)
 }
 
+// newTemplate returns an empty template with the template functions and
+// --template-delims, which fails on missing params with --strict-templates.
+func newTemplate(name string) *template.Template {
+	tmpl := template.New(name).Delims(delims()).Funcs(dateFuncs).Funcs(lookupFuncs)
+	if *strictTemplates {
+		tmpl.Option("missingkey=error")
+	}
+	return tmpl
+}
+
+// checkNoValue fails if any of the rendered texts contain "<no value>",
+// which missingkey=error doesn't catch for missing struct fields.
+func checkNoValue(rendered ...string) error {
+	for _, s := range rendered {
+		if strings.Contains(s, "<no value>") {
+			return fmt.Errorf("rendered <no value>, from a missing param")
+		}
+	}
+	return nil
+}
+
+// createIssues renders each ticket through the summary and description
+// templates and hands the results to the tracker to be created.
 func createIssues(
-	client *jira.Client,
+	tracker Tracker,
 	summaryTemplate *template.Template,
 	descriptionTemplate *template.Template,
 	tickets []Ticket,
-	epic *jira.Epic,
+	epics *epicResolver,
+	run *Run,
 ) error {
 	summaryBuf := bytes.NewBufferString("")
 	descriptionBuf := bytes.NewBufferString("")
 
-	projectCache := make(map[string]*jira.Project, 0)
-	for _, ticket := range tickets {
-		summaryBuf.Reset()
-		descriptionBuf.Reset()
+	assign, err := newAssigner()
+	if err != nil {
+		return err
+	}
+	filter, err := newTicketFilter()
+	if err != nil {
+		return err
+	}
+	fieldsTemplate, err := loadFieldsTemplate()
+	if err != nil {
+		return err
+	}
 
-		_, ok := projectCache[ticket.Project]
-		if !ok {
-			project, resp, err := client.Project.Get(ticket.Project)
-			if err != nil {
-				return jiraAPIRequestErrorHandler(resp, err)
-			}
-			projectCache[ticket.Project] = project
+	if err := checkDependencies(tickets); err != nil {
+		return err
+	}
+
+	selected := make([]Issue, 0, len(tickets))
+	inferred := make(map[int]bool)
+	problems := make([]string, 0)
+	timings.Start("rendering")
+	for i, ticket := range tickets {
+		if ticket.Params == nil {
+			ticket.Params = make(map[string]interface{})
 		}
-		project, _ := projectCache[ticket.Project]
-		if len(project.IssueTypes) == 0 {
-			fmt.Fprint(
-				os.Stderr,
-				"No issue types found for project %s - Skipping creating %v\n",
-				ticket.Project,
-				ticket,
-			)
+		applyParamOverrides(&ticket)
+		applyRunFields(&ticket)
+		if *runLabel {
+			ticket.Labels = mergeNames(ticket.Labels, []string{runLabelName(run)})
 		}
-		issueType := project.IssueTypes[0]
-
-		ticket.Params["epic"] = epic.Key
-		// write template into buf
-		err := summaryTemplate.Execute(summaryBuf, ticket)
+		ticketEpic, err := epics.ForTicket(ticket)
 		if err != nil {
-			return err
+			return fmt.Errorf("ticket %d: %v", i, err)
 		}
-		err = descriptionTemplate.Execute(descriptionBuf, ticket)
+		project, projectInferred, err := ticketProject(ticket, ticketEpic)
 		if err != nil {
+			return fmt.Errorf("ticket %d: %v", i, err)
+		}
+		ticket.Project = project
+		if projectInferred {
+			inferred[i] = true
+		}
+		if err := checkTicketProject(i, ticket, ticketEpic); err != nil {
 			return err
 		}
+		if err := applyProjectConfig(&ticket); err != nil {
+			return fmt.Errorf("ticket %d: %v", i, err)
+		}
+		ticket.Params["epic"] = ticketEpic.Key
+		enabled, err := ticketEnabled(newTicketContext(ticket, ticketEpic))
+		if err != nil {
+			return fmt.Errorf("ticket %d: when: %v", i, err)
+		}
+		if !enabled {
+			fmt.Printf("Skipped ticket %d: %q is false\n", i, ticket.When)
+			continue
+		}
+		if !filter.Selects(i, ticket) {
+			continue
+		}
+		if ticket.Assignee == "" && assign != nil {
+			ticket.Assignee = assign.Next()
+		}
+		if err := resolveTicketUsers(tracker, &ticket); err != nil {
+			return fmt.Errorf("ticket %d: %v", i, err)
+		}
+		selected = append(selected, Issue{Index: i, Ticket: ticket, Epic: ticketEpic})
+	}
+
+	// Tickets are only numbered once it's known which are selected, and
+	// in what order they'll be created.
+	selected = orderByDependencies(selected)
+	for n := range selected {
+		dueDate, err := scheduledDueDate(selected[n].Ticket, n)
+		if err != nil {
+			return fmt.Errorf("ticket %d: %v", selected[n].Index, err)
+		}
+		selected[n].Ticket.DueDate = dueDate
+	}
+	if err := scheduleIssues(tracker, selected); err != nil {
+		return err
+	}
+	issues := make([]Issue, 0, len(selected))
+	for n, s := range selected {
+		i, ticket, ticketEpic := s.Index, s.Ticket, s.Epic
+		ticket.Index = n + 1
+		ticket.Total = len(selected)
+		if n > 0 {
+			ticket.PrevKey = prevKeyMarker
+		}
+		summaryBuf.Reset()
+		descriptionBuf.Reset()
 
-		// create issue struct
-		fields := jira.IssueFields{
+		// write template into buf
+		ctx := newTicketContext(ticket, ticketEpic)
+		err = summaryTemplate.Execute(summaryBuf, ctx)
+		if err == nil {
+			err = descriptionTemplate.Execute(descriptionBuf, ctx)
+		}
+		var comment string
+		if err == nil {
+			comment, err = renderComment(ctx)
+		}
+		var fields map[string]interface{}
+		if err == nil {
+			fields, err = renderFields(fieldsTemplate, ctx)
+		}
+		if err == nil && *strictTemplates {
+			err = checkNoValue(summaryBuf.String(), descriptionBuf.String(), comment)
+		}
+		issue := Issue{
+			Index:       i,
+			Ticket:      ticket,
+			Epic:        ticketEpic,
 			Summary:     summaryBuf.String(),
 			Description: descriptionBuf.String(),
-			Type:        issueType,
-			Project:     *project,
+			Comment:     comment,
+			Fields:      fields,
+		}
+		sanitizeIssue(&issue)
+		if err == nil {
+			err = applyLengthPolicy(&issue)
+		}
+		if err == nil {
+			err = defaultRequiredFields(tracker, &issue)
 		}
-		if ticket.CustomEpicField != "" {
-			fields.Unknowns = tcontainer.MarshalMap{
-				ticket.CustomEpicField: epic.Key,
+		if err != nil {
+			// Strict templates check every ticket before failing, so
+			// all of them can be fixed at once.
+			if !*strictTemplates {
+				return fmt.Errorf("ticket %d: %v", i, err)
 			}
-		} else {
-			fields.Epic = epic
+			problems = append(problems, fmt.Sprintf("ticket %d: %v", i, err))
+			continue
 		}
-		issue := jira.Issue{Fields: &fields}
 
-		// make request
-		createdIssue, resp, err := client.Issue.Create(&issue)
+		issues = append(issues, issue)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf(
+			"%d ticket(s) don't satisfy the templates:\n%s",
+			len(problems),
+			strings.Join(problems, "\n"),
+		)
+	}
+	if len(issues) < len(tickets) {
+		fmt.Printf("Selected %d of %d ticket(s)\n", len(issues), len(tickets))
+	}
+	reportParamUsage([]*template.Template{summaryTemplate, descriptionTemplate, fieldsTemplate}, issues)
+	rendered = issues
+	if watched != nil {
+		issues, err = watched.Sync(tracker, issues)
 		if err != nil {
-			return jiraAPIRequestErrorHandler(resp, err)
+			return err
 		}
-		fmt.Printf(
-			"Created: %v\nIssue Fields: %v\n",
-			*createdIssue,
-			createdIssue.Fields,
-		)
 	}
-	return nil
-}
-
-func getEpic(client *jira.Client, epicName string) (*jira.Epic, error) {
-	issue, resp, err := client.Issue.Get(
-		epicName,
-		&jira.GetQueryOptions{
-			Fields: "issuetype",
-		},
-	)
-
+	if *renderOut != "" {
+		if err := writeRendered(*renderOut, issues); err != nil {
+			return err
+		}
+	}
+	if err := checkRunPermissions(tracker, issues); err != nil {
+		return err
+	}
+	if *dryRun {
+		printPlan(issues, inferred)
+		return nil
+	}
+	err = runPreCreateHooks(issues, run)
 	if err != nil {
-		return nil, jiraAPIRequestErrorHandler(resp, err)
+		return err
 	}
-
-	if issue.Fields.Type.Name != "Epic" {
-		return nil, err
+	timings.Start("creation")
+	if watched != nil {
+		// Record what was created even if the run fails.
+		defer watched.Record(issues, run)
 	}
-
-	id, err := strconv.Atoi(issue.ID)
+	err = createBatches(tracker, uncreated(issues, run), run)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	epic := &jira.Epic{
-		ID:   id,
-		Self: issue.Self,
-		Key:  issue.Key,
+	groups := groupByEpic(issues)
+	timings.Start("post-create")
+	err = runPostCreateSteps(tracker, issues, run)
+	if err != nil {
+		return err
+	}
+	if *rank {
+		if err := rankCreated(tracker, run); err != nil {
+			return err
+		}
+	}
+	timings.Start("linking")
+	if err := linkDependencies(tracker, issues, run); err != nil {
+		return err
 	}
-	return epic, nil
+	if *chainLinks {
+		if err := linkChain(tracker, run); err != nil {
+			return err
+		}
+	}
+	timings.Start("post-create")
+	for _, group := range groups {
+		created := group.created(run)
+		if *epicChecklist && len(created) > 0 {
+			if err := updateEpicChecklist(tracker, group.epic, created); err != nil {
+				return err
+			}
+		}
+		if *epicComment && len(created) > 0 {
+			if err := commentOnEpic(tracker, group.epic, group.issues, run, created); err != nil {
+				return err
+			}
+		}
+		if *confluenceSpace != "" && len(created) > 0 {
+			if err := publishConfluencePage(group.epic, group.issues, run, created); err != nil {
+				return err
+			}
+		}
+		printPointsRollUp(group.epic, group.issues, created)
+	}
+	if *boardName != "" && len(run.Created) > 0 {
+		if err := showOnBoard(tracker, groups); err != nil {
+			return err
+		}
+	}
+	if len(run.Created) > 0 {
+		if err := printCreatedQuery(tracker, run); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type Creds struct {
@@ -176,8 +439,20 @@ type Creds struct {
 	Password string
 }
 
+// getCreds reads the credentials from --vault-secret or --aws-secret, if
+// either is given, and otherwise from the auth file, or from ~/.netrc if
+// there is no auth file.
 func getCreds(authFilePath string) (*Creds, error) {
-	data, err := ioutil.ReadFile(authFilePath)
+	if creds, err := credsFromSecret(); creds != nil || err != nil {
+		return creds, err
+	}
+
+	data, err := readAuthFile(authFilePath)
+	if os.IsNotExist(err) {
+		if creds, netrcErr := netrcCreds(); creds != nil || netrcErr != nil {
+			return creds, netrcErr
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -187,55 +462,174 @@ func getCreds(authFilePath string) (*Creds, error) {
 	return &creds, err
 }
 
-func main() {
-	workdir, err := os.Getwd()
+// trackerLogin is the user the tracker logged in as, for the audit log.
+var trackerLogin string
+
+// trackerCreds resolves the tracker's credentials, keeping their user as
+// trackerLogin.
+func trackerCreds() (*Creds, error) {
+	creds, err := getCreds(*authFilePath)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+	trackerLogin = creds.User
+	return creds, nil
+}
 
-	url := kingpin.Flag(
+var (
+	workdir = getWorkdir()
+
+	backend = kingpin.Flag(
+		"backend",
+		"Issue tracker to create issues in: jira, github, gitlab, azure or linear.",
+	).Default("jira").String()
+	jiraURL = kingpin.Flag(
 		"jira-url",
 		"JIRA instance URL",
 	).URL()
-	authFilePath := kingpin.Flag(
+	jiraADF = kingpin.Flag(
+		"adf",
+		"Submit descriptions to JIRA as Atlassian Document Format through the v3 API. Implied on Jira Cloud.",
+	).Bool()
+	authFilePath = kingpin.Flag(
 		"auth-file",
-		"Path to JSON file with auth credentials. Must have <user> and <password>.",
+		"Path to JSON file with auth credentials. Must have <user> and <password>. May be encrypted with age (.age) or GPG (.gpg, .asc).",
 	).Default(
 		path.Join(workdir, "auth.json"),
-	).ExistingFile()
-	ticketsFilePath := kingpin.Flag(
+	).String()
+	stateDir = kingpin.Flag(
+		"state-dir",
+		"Directory in which a record of each run is kept.",
+	).Default(
+		path.Join(workdir, ".epic-creator"),
+	).String()
+
+	createCmd = kingpin.Command(
+		"create",
+		"Create issues in an Epic.",
+	).Default()
+	ticketsFilePath = createCmd.Flag(
 		"tickets-json",
 		ticketsHelp,
 	).Default(
 		path.Join(workdir, "tickets.json"),
-	).ExistingFile()
-	summaryTemplatePath := kingpin.Flag(
+	).String()
+	summaryTemplatePath = createCmd.Flag(
 		"summary-template",
 		"Path to template to use for summary of Issues created in the Epic.",
 	).Default(
 		path.Join(workdir, "summary.jira.tmpl"),
 	).ExistingFile()
-	descriptionTemplatePath := kingpin.Flag(
+	descriptionTemplatePath = createCmd.Flag(
 		"description-template",
 		"Path to template to use for description of Issues created in the Epic.",
 	).Default(
 		path.Join(workdir, "description.jira.tmpl"),
 	).ExistingFile()
-	epicName := kingpin.Arg("epic", "Epic to create issues in.").Required().String()
-
-	kingpin.Parse()
+	strictTemplates = createCmd.Flag(
+		"strict-templates",
+		"Fail, before creating anything, if any ticket's params are missing a key the templates use.",
+	).Bool()
+	descriptionFormat = createCmd.Flag(
+		"description-format",
+		"Markup the description template is written in. Markdown is converted to JIRA wiki markup before submission.",
+	).Default("wiki").Enum("wiki", "markdown")
+	transition = createCmd.Flag(
+		"transition",
+		"Name of a workflow transition to move every issue through once it's created, e.g. \"Ready for Dev\".",
+	).String()
+	dueStart = createCmd.Flag(
+		"due-start",
+		"Date (YYYY-MM-DD) on which the first ticket without a due_date is due. Later tickets are due --due-interval apart.",
+	).String()
+	dueInterval = createCmd.Flag(
+		"due-interval",
+		"Interval between the due dates of consecutive tickets, e.g. 3d, 1w or 36h.",
+	).Default("1d").String()
+	assignees = createCmd.Flag(
+		"assignees",
+		"Comma-separated users to spread tickets without an assignee across. May be repeated.",
+	).Strings()
+	rosterFilePath = createCmd.Flag(
+		"roster",
+		"Path to a JSON list of {\"user\": ..., \"weight\": ...} to spread tickets without an assignee across.",
+	).ExistingFile()
+	assignStrategy = createCmd.Flag(
+		"assign-strategy",
+		"How to spread tickets across --assignees or --roster.",
+	).Default("round-robin").Enum("round-robin", "weighted")
+	rank = createCmd.Flag(
+		"rank",
+		"Rank the created issues in the backlog in the same order as the tickets file.",
+	).Bool()
+	epicChecklist = createCmd.Flag(
+		"epic-checklist",
+		"Keep a checklist of created issues in the epic's description.",
+	).Bool()
+	epicComment = createCmd.Flag(
+		"epic-comment",
+		"Once every issue is created, post a comment on the epic listing them.",
+	).Bool()
+	epicCommentTemplatePath = createCmd.Flag(
+		"epic-comment-template",
+		"Path to template to use for the --epic-comment, instead of the default table.",
+	).ExistingFile()
+	notifyWebhook = createCmd.Flag(
+		"notify-webhook",
+		"Incoming webhook URL to post a summary of the run to once it finishes.",
+	).String()
+	notifyFormat = createCmd.Flag(
+		"notify-format",
+		"Payload format of the --notify-webhook.",
+	).Default("slack").Enum("slack", "teams")
+	reportWebhook = createCmd.Flag(
+		"report-webhook",
+		"URL to POST the run's JSON report to once it finishes.",
+	).String()
+	reportWebhookSecret = createCmd.Flag(
+		"report-webhook-secret",
+		"Secret with which to sign --report-webhook requests.",
+	).Envar("EPIC_CREATOR_WEBHOOK_SECRET").String()
+	preCreateHook = createCmd.Flag(
+		"pre-create-hook",
+		"Shell command run for each rendered issue, as JSON on stdin, before any are created. A failing hook stops the run.",
+	).String()
+	postCreateHookCommand = createCmd.Flag(
+		"post-create-hook",
+		"Shell command run for each created issue, as JSON on stdin.",
+	).String()
+	transactional = createCmd.Flag(
+		"transactional",
+		"Roll back every issue created so far if the run fails partway through.",
+	).Bool()
+	bulk = createCmd.Flag(
+		"bulk",
+		"Create issues in batches through the bulk create API, where the instance supports it.",
+	).Default("true").Bool()
+	epicName = createCmd.Arg("epic", "Epic to create issues in. May be left out with --hierarchy, whose first epic is then used.").String()
+)
 
-	creds, err := getCreds(*authFilePath)
+func getWorkdir() string {
+	workdir, err := os.Getwd()
 	if err != nil {
 		panic(err)
 	}
+	return workdir
+}
 
-	client, err := jira.NewClient(nil, (*url).String())
+func runCreate() {
+	if *quiet {
+		if err := silenceStdout(); err != nil {
+			panic(err)
+		}
+	}
+	timings.Start("auth")
+	tracker, err := newTracker(*backend)
 	if err != nil {
 		panic(err)
 	}
-	client.Authentication.SetBasicAuth(creds.User, creds.Password)
 
+	timings.Start("loading")
 	summaryTemplate, err := loadTemplate(*summaryTemplatePath)
 	if err != nil {
 		panic(err)
@@ -244,28 +638,201 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	if err := loadProjectConfig(); err != nil {
+		panic(err)
+	}
 
-	tickets, err := loadTickets(*ticketsFilePath)
+	var tickets []Ticket
+	if *ticketsGSheet != "" {
+		tickets, err = loadSheetTickets(*ticketsGSheet)
+	} else {
+		tickets, err = loadTickets(*ticketsFilePath)
+	}
 	if err != nil {
 		panic(err)
 	}
-	epic, err := getEpic(client, *epicName)
+	timings.Start("metadata")
+	if p, ok := tracker.(prefetcher); ok {
+		if err := p.Prefetch(tickets); err != nil {
+			panic(err)
+		}
+	}
+	epics := newEpicResolver(tracker, nil)
+	var epic *Epic
+	if *epicName != "" {
+		epic, err = epics.Resolve(*epicName)
+		if err != nil {
+			panic(err)
+		}
+		epics.Add(epic.Key, epic)
+	} else if *hierarchyPath == "" {
+		panic(fmt.Errorf("an epic is required unless --hierarchy is given"))
+	}
+	var h *hierarchy
+	if *hierarchyPath != "" {
+		h, err = loadHierarchy(*hierarchyPath)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	runEpic := *epicName
+	if epic != nil {
+		runEpic = epic.Key
+	}
+	if *dryRun {
+		if h != nil {
+			if err := planHierarchy(h, epics); err != nil {
+				panic(err)
+			}
+		}
+		err = createIssues(
+			tracker,
+			summaryTemplate,
+			descriptionTemplate,
+			tickets,
+			epics,
+			newRun(*stateDir, *backend, runEpic),
+		)
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	run, err := startRun(runEpic, h)
 	if err != nil {
 		panic(err)
 	}
-	if epic == nil {
-		fmt.Fprintf(os.Stderr, "Found %s but it was not an epic.\n", *epicName)
+	fmt.Printf("Run ID: %s\n", run.ID)
+	locks, err := lockEpics(tracker, run, runEpics(epic, tickets, h))
+	if err != nil {
 		panic(err)
 	}
-
-	err = createIssues(
-		client,
-		summaryTemplate,
-		descriptionTemplate,
-		tickets,
-		epic,
-	)
+	defer locks.Release()
+
+	if h != nil {
+		timings.Start("creation")
+		err = createHierarchy(tracker, h, epics, run)
+		if epic == nil && epics.Default() != nil {
+			epic = epics.Default()
+			run.Epic = epic.Key
+		}
+	}
+	if err == nil {
+		err = createIssues(
+			tracker,
+			summaryTemplate,
+			descriptionTemplate,
+			tickets,
+			epics,
+			run,
+		)
+	}
+	if epic == nil {
+		// The hierarchy failed before its first epic was created.
+		epic = &Epic{}
+	}
+	if finishErr := run.Finish(err); finishErr != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save run: %v\n", finishErr)
+	}
+	timings.Print(len(run.Created))
+	if *reportHTMLPath != "" {
+		if reportErr := writeHTMLReport(*reportHTMLPath, epic, run); reportErr != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write HTML report: %v\n", reportErr)
+		}
+	}
+	if *reportJUnitPath != "" {
+		if reportErr := writeJUnitReport(*reportJUnitPath, run); reportErr != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write JUnit report: %v\n", reportErr)
+		}
+	}
+	if ghErr := writeGitHubOutputs(epic, run); ghErr != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write GitHub Actions outputs: %v\n", ghErr)
+	}
+	entry := newAuditEntry("create", run, createdKeys(run.Created), err)
+	entry.TicketsFile = *ticketsFilePath
+	entry.TicketsHash = hashFile(*ticketsFilePath)
+	if *ticketsGSheet != "" {
+		entry.TicketsFile = *ticketsGSheet
+		entry.TicketsHash = ""
+	}
+	if auditErr := appendAudit(entry); auditErr != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write audit log: %v\n", auditErr)
+	}
+	if pushErr := pushMetrics(run, err); pushErr != nil {
+		fmt.Fprintf(os.Stderr, "Failed to push metrics: %v\n", pushErr)
+	}
+	if *reportWebhook != "" {
+		if reportErr := postReport(*reportWebhook, *reportWebhookSecret, run); reportErr != nil {
+			fmt.Fprintf(os.Stderr, "Failed to post run report: %v\n", reportErr)
+		}
+	}
+	if *notifyWebhook != "" {
+		if notifyErr := notify(epic, run, err); notifyErr != nil {
+			fmt.Fprintf(os.Stderr, "Failed to send notification: %v\n", notifyErr)
+		}
+	}
 	if err != nil {
+		if *transactional {
+			fmt.Fprintf(os.Stderr, "Run failed, rolling back %d issue(s): %v\n", len(run.Created), err)
+			if rbErr := auditedRollback(tracker, run, ""); rbErr != nil {
+				fmt.Fprintf(os.Stderr, "Rollback failed: %v\n", rbErr)
+			}
+		}
+		// Panic rather than exit, so the deferred calls, like releasing
+		// the locks, still run.
+		panic(runFailure{err})
+	}
+}
+
+func main() {
+	defer exitOnRunFailure()
+	kingpin.Version(toolVersion())
+	command := kingpin.Parse()
+	if err := checkTemplateDelims(); err != nil {
 		panic(err)
 	}
+	if err := configureJIRARecording(); err != nil {
+		panic(err)
+	}
+	configureMetrics()
+
+	switch command {
+	case createCmd.FullCommand():
+		if *watch {
+			runWatch()
+		} else if *schedule != "" {
+			runSchedule()
+		} else {
+			stopOnSignal()
+			runCreate()
+		}
+	case rollbackCmd.FullCommand():
+		runRollback()
+	case importCSVCmd.FullCommand():
+		runImportCSV()
+	case importMarkdownCmd.FullCommand():
+		runImportMarkdown()
+	case mockServerCmd.FullCommand():
+		runMockServer()
+	case templateTestCmd.FullCommand():
+		runTemplateTest()
+	case schemaCmd.FullCommand():
+		runSchema()
+	case statusCmd.FullCommand():
+		runStatus()
+	case bulkTransitionCmd.FullCommand(), bulkLabelCmd.FullCommand(), bulkAssignCmd.FullCommand(), bulkCommentCmd.FullCommand():
+		runBulk(command)
+	case moveCmd.FullCommand():
+		runMove()
+	case reportCmd.FullCommand():
+		runEpicReport()
+	case serveCmd.FullCommand():
+		runServe()
+	case completionCmd.FullCommand():
+		runCompletion()
+	case versionCmd.FullCommand():
+		runVersion()
+	}
 }
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// A small Markdown reader covering what people actually put in ticket
+// descriptions: headings, paragraphs, lists, fenced code, quotes, rules,
+// tables, and the usual inline emphasis, code and links. Documents are read
+// into a flat list of blocks, whose inline text is then read into spans by
+// the parser for the same markup. Blocks and spans can be rendered to JIRA
+// wiki markup or, see adf.go, to Atlassian Document Format.
+
+type blockKind int
+
+const (
+	blockParagraph blockKind = iota
+	blockHeading
+	blockListItem
+	blockCode
+	blockQuote
+	blockRule
+	blockTableRow
+)
+
+type block struct {
+	kind blockKind
+	// level is the heading level, or the nesting depth (from 1) of a list
+	// item.
+	level int
+	// ordered is set on items of numbered lists.
+	ordered bool
+	// header is set on table rows which are the table's header.
+	header bool
+	// lang is the language of a code block, if given.
+	lang string
+	// text is the inline content of the block, or the body of a code block.
+	text string
+	// cells are the inline contents of a table row.
+	cells []string
+}
+
+var (
+	mdFence     = regexp.MustCompile("^\\s*(```|~~~)\\s*(\\S*)")
+	mdHeading   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdRule      = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	mdListItem  = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	mdQuote     = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	mdTableSep  = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	mdTableLine = regexp.MustCompile(`^\s*\|`)
+)
+
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+
+	cells := strings.Split(line, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+// parseMarkdown reads a Markdown document into blocks.
+func parseMarkdown(src string) []block {
+	lines := strings.Split(strings.Replace(src, "\r\n", "\n", -1), "\n")
+	blocks := make([]block, 0)
+
+	// indents holds the indentation of each open level of list nesting.
+	indents := make([]int, 0)
+	// open is the paragraph, list item or quote which following lines
+	// continue, if any.
+	var open *block
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if m := mdFence.FindStringSubmatch(line); m != nil {
+			body := make([]string, 0)
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), m[1]); i++ {
+				body = append(body, lines[i])
+			}
+			blocks = append(blocks, block{kind: blockCode, lang: m[2], text: strings.Join(body, "\n")})
+			open = nil
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			open = nil
+			continue
+		}
+
+		if m := mdHeading.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, block{kind: blockHeading, level: len(m[1]), text: m[2]})
+			open = nil
+			continue
+		}
+
+		if mdRule.MatchString(line) {
+			blocks = append(blocks, block{kind: blockRule})
+			open = nil
+			continue
+		}
+
+		if m := mdListItem.FindStringSubmatch(line); m != nil {
+			indent := len(m[1])
+			if open == nil || open.kind != blockListItem {
+				indents = indents[:0]
+			}
+			for len(indents) > 0 && indent < indents[len(indents)-1] {
+				indents = indents[:len(indents)-1]
+			}
+			if len(indents) == 0 || indent > indents[len(indents)-1] {
+				indents = append(indents, indent)
+			}
+
+			ordered := m[2] != "-" && m[2] != "*" && m[2] != "+"
+			blocks = append(blocks, block{kind: blockListItem, level: len(indents), ordered: ordered, text: m[3]})
+			open = &blocks[len(blocks)-1]
+			continue
+		}
+
+		if mdTableLine.MatchString(line) {
+			if mdTableSep.MatchString(line) {
+				if n := len(blocks); n > 0 && blocks[n-1].kind == blockTableRow {
+					blocks[n-1].header = true
+				}
+			} else {
+				blocks = append(blocks, block{kind: blockTableRow, cells: splitTableRow(line)})
+			}
+			open = nil
+			continue
+		}
+
+		if m := mdQuote.FindStringSubmatch(line); m != nil {
+			if open != nil && open.kind == blockQuote {
+				open.text += " " + m[1]
+				continue
+			}
+			blocks = append(blocks, block{kind: blockQuote, text: m[1]})
+			open = &blocks[len(blocks)-1]
+			continue
+		}
+
+		if open != nil {
+			open.text += " " + strings.TrimSpace(line)
+			continue
+		}
+		blocks = append(blocks, block{kind: blockParagraph, text: strings.TrimSpace(line)})
+		open = &blocks[len(blocks)-1]
+	}
+	return blocks
+}
+
+type spanKind int
+
+const (
+	spanText spanKind = iota
+	spanStrong
+	spanEm
+	spanStrike
+	spanCode
+	spanLink
+	spanImage
+)
+
+// span is a run of inline content. Text and code spans carry text; link and
+// image spans carry a url; the rest carry children.
+type span struct {
+	kind     spanKind
+	text     string
+	url      string
+	children []span
+}
+
+var mdLink = regexp.MustCompile(`^(!?)\[([^\]]*)\]\(([^)\s]+)\)`)
+
+// inlineDelims are the Markdown emphasis delimiters, longest first so that
+// "**" is not read as two "*".
+var inlineDelims = []struct {
+	delim string
+	kind  spanKind
+}{
+	{"**", spanStrong},
+	{"__", spanStrong},
+	{"~~", spanStrike},
+	{"*", spanEm},
+	{"_", spanEm},
+}
+
+// inlineParser reads the inline content of a block into spans.
+type inlineParser func(s string) []span
+
+// parseMarkdownInline reads Markdown inline content into spans.
+func parseMarkdownInline(s string) []span {
+	spans := make([]span, 0)
+	text := ""
+	flush := func() {
+		if text != "" {
+			spans = append(spans, span{kind: spanText, text: text})
+			text = ""
+		}
+	}
+
+	for i := 0; i < len(s); {
+		rest := s[i:]
+
+		if rest[0] == '\\' && len(rest) > 1 {
+			text += rest[1:2]
+			i += 2
+			continue
+		}
+
+		if rest[0] == '`' {
+			if end := strings.Index(rest[1:], "`"); end >= 0 {
+				flush()
+				spans = append(spans, span{kind: spanCode, text: rest[1 : end+1]})
+				i += end + 2
+				continue
+			}
+		}
+
+		if m := mdLink.FindStringSubmatch(rest); m != nil {
+			flush()
+			if m[1] == "!" {
+				spans = append(spans, span{kind: spanImage, text: m[2], url: m[3]})
+			} else {
+				spans = append(spans, span{kind: spanLink, url: m[3], children: parseMarkdownInline(m[2])})
+			}
+			i += len(m[0])
+			continue
+		}
+
+		matched := false
+		for _, d := range inlineDelims {
+			if !strings.HasPrefix(rest, d.delim) {
+				continue
+			}
+			// Intraword underscores (snake_case) are not emphasis.
+			if d.delim[0] == '_' && i > 0 && isWordByte(s[i-1]) {
+				break
+			}
+			end := strings.Index(rest[len(d.delim):], d.delim)
+			if end <= 0 {
+				break
+			}
+			flush()
+			inner := rest[len(d.delim) : len(d.delim)+end]
+			spans = append(spans, span{kind: d.kind, children: parseMarkdownInline(inner)})
+			i += end + 2*len(d.delim)
+			matched = true
+			break
+		}
+		if matched {
+			continue
+		}
+
+		text += rest[:1]
+		i++
+	}
+	flush()
+	return spans
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+var wikiEscaper = strings.NewReplacer(
+	"{", "\\{",
+	"}", "\\}",
+	"[", "\\[",
+	"]", "\\]",
+)
+
+func renderWikiInline(spans []span) string {
+	var out bytes.Buffer
+	for _, sp := range spans {
+		switch sp.kind {
+		case spanText:
+			out.WriteString(wikiEscaper.Replace(sp.text))
+		case spanStrong:
+			out.WriteString("*" + renderWikiInline(sp.children) + "*")
+		case spanEm:
+			out.WriteString("_" + renderWikiInline(sp.children) + "_")
+		case spanStrike:
+			out.WriteString("-" + renderWikiInline(sp.children) + "-")
+		case spanCode:
+			out.WriteString("{{" + sp.text + "}}")
+		case spanLink:
+			out.WriteString("[" + renderWikiInline(sp.children) + "|" + sp.url + "]")
+		case spanImage:
+			out.WriteString("!" + sp.url + "!")
+		}
+	}
+	return out.String()
+}
+
+// renderWiki renders blocks as JIRA wiki markup.
+func renderWiki(blocks []block, inline inlineParser) string {
+	var out bytes.Buffer
+	// markers holds the list marker for each level of the current list.
+	markers := make([]string, 0)
+
+	for i, b := range blocks {
+		if i > 0 {
+			// Consecutive list items and table rows stay together; any
+			// other blocks are separated by a blank line.
+			prev := blocks[i-1].kind
+			if prev == b.kind && (b.kind == blockListItem || b.kind == blockTableRow) {
+				out.WriteString("\n")
+			} else {
+				out.WriteString("\n\n")
+			}
+		}
+		if b.kind != blockListItem {
+			markers = markers[:0]
+		}
+
+		switch b.kind {
+		case blockParagraph:
+			out.WriteString(renderWikiInline(inline(b.text)))
+		case blockHeading:
+			fmt.Fprintf(&out, "h%d. %s", b.level, renderWikiInline(inline(b.text)))
+		case blockListItem:
+			marker := "*"
+			if b.ordered {
+				marker = "#"
+			}
+			if len(markers) >= b.level {
+				markers = markers[:b.level-1]
+			}
+			for len(markers) < b.level-1 {
+				markers = append(markers, marker)
+			}
+			markers = append(markers, marker)
+			out.WriteString(strings.Join(markers, "") + " " + renderWikiInline(inline(b.text)))
+		case blockCode:
+			if b.lang != "" {
+				fmt.Fprintf(&out, "{code:%s}\n%s\n{code}", b.lang, b.text)
+			} else {
+				fmt.Fprintf(&out, "{noformat}\n%s\n{noformat}", b.text)
+			}
+		case blockQuote:
+			out.WriteString("bq. " + renderWikiInline(inline(b.text)))
+		case blockRule:
+			out.WriteString("----")
+		case blockTableRow:
+			sep := "|"
+			if b.header {
+				sep = "||"
+			}
+			cells := make([]string, len(b.cells))
+			for i, cell := range b.cells {
+				cells[i] = renderWikiInline(inline(cell))
+			}
+			out.WriteString(sep + strings.Join(cells, sep) + sep)
+		}
+	}
+	return out.String()
+}
+
+// markdownToWiki converts a Markdown document to JIRA wiki markup.
+func markdownToWiki(src string) string {
+	return renderWiki(parseMarkdown(src), parseMarkdownInline)
+}
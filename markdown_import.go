@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	importMarkdownCmd = kingpin.Command(
+		"import-markdown",
+		"Convert the task list items of a Markdown document to a tickets file.",
+	)
+	importMarkdownProject = importMarkdownCmd.Flag(
+		"project",
+		"Project to create every ticket in.",
+	).Required().String()
+	importMarkdownHeadingAs = importMarkdownCmd.Flag(
+		"heading-as",
+		"What the heading above each item becomes on its ticket.",
+	).Default("label").Enum("label", "component", "none")
+	importMarkdownOutput = importMarkdownCmd.Flag(
+		"output",
+		"Path to write the tickets file to, instead of stdout.",
+	).Short('o').String()
+	importMarkdownFile = importMarkdownCmd.Arg("markdown", "Markdown document to convert.").Required().ExistingFile()
+)
+
+var (
+	mdTaskItem   = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	labelInvalid = regexp.MustCompile(`\s+`)
+)
+
+// markdownTickets makes a ticket of each unchecked task list item
+// ("- [ ] ...") in a Markdown document. The item's text is its summary
+// param, and the nearest heading above it its heading param and, per
+// headingAs, a label or component. Checked items are taken to be done
+// already and are skipped.
+func markdownTickets(src string, project string, headingAs string) []Ticket {
+	tickets := make([]Ticket, 0)
+	heading := ""
+	for _, b := range parseMarkdown(src) {
+		switch b.kind {
+		case blockHeading:
+			heading = b.text
+		case blockListItem:
+			m := mdTaskItem.FindStringSubmatch(b.text)
+			if m == nil || m[1] != " " {
+				continue
+			}
+
+			ticket := Ticket{
+				Project: project,
+				Params: map[string]interface{}{
+					"summary": m[2],
+					"heading": heading,
+				},
+			}
+			if heading != "" {
+				switch headingAs {
+				case "label":
+					// Labels can't contain spaces.
+					ticket.Labels = []string{labelInvalid.ReplaceAllString(strings.ToLower(heading), "-")}
+				case "component":
+					ticket.Components = []string{heading}
+				}
+			}
+			tickets = append(tickets, ticket)
+		}
+	}
+	return tickets
+}
+
+func runImportMarkdown() {
+	src, err := ioutil.ReadFile(*importMarkdownFile)
+	if err != nil {
+		panic(err)
+	}
+	tickets := markdownTickets(string(src), *importMarkdownProject, *importMarkdownHeadingAs)
+
+	data, err := json.MarshalIndent(tickets, "", "    ")
+	if err != nil {
+		panic(err)
+	}
+	data = append(data, '\n')
+
+	if *importMarkdownOutput == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := ioutil.WriteFile(*importMarkdownOutput, data, 0644); err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d ticket(s) to %s\n", len(tickets), *importMarkdownOutput)
+}
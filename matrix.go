@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// expandMatrix replaces each ticket which has a matrix with one ticket per
+// combination of the matrix's values, with the combination merged into the
+// ticket's params. Keys are combined in sorted order, with the last varying
+// fastest, so the expansion is stable between runs. The ticket's id and
+// depends_on are rendered for each combination, and each must get its own id.
+func expandMatrix(tickets []Ticket) ([]Ticket, error) {
+	expanded := make([]Ticket, 0, len(tickets))
+	for i, ticket := range tickets {
+		if err := matrixFromParams(&ticket); err != nil {
+			return nil, fmt.Errorf("ticket %d: %v", i, err)
+		}
+		if len(ticket.Matrix) == 0 {
+			expanded = append(expanded, ticket)
+			continue
+		}
+
+		keys := make([]string, 0, len(ticket.Matrix))
+		for key := range ticket.Matrix {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		combinations := []map[string]interface{}{{}}
+		for _, key := range keys {
+			next := make([]map[string]interface{}, 0, len(combinations)*len(ticket.Matrix[key]))
+			for _, combination := range combinations {
+				for _, value := range ticket.Matrix[key] {
+					c := make(map[string]interface{}, len(combination)+1)
+					for k, v := range combination {
+						c[k] = v
+					}
+					c[key] = value
+					next = append(next, c)
+				}
+			}
+			combinations = next
+		}
+
+		rows := make(map[string]int)
+		for row, combination := range combinations {
+			t := ticket
+			t.Matrix = nil
+			t.Params = make(map[string]interface{}, len(ticket.Params)+len(combination))
+			for k, v := range ticket.Params {
+				t.Params[k] = v
+			}
+			for k, v := range combination {
+				t.Params[k] = v
+			}
+			if err := renderMatrixIDs(&t); err != nil {
+				return nil, fmt.Errorf("ticket %d: %v", i, err)
+			}
+			if t.ID != "" {
+				if other, ok := rows[t.ID]; ok {
+					return nil, fmt.Errorf(
+						"ticket %d: matrix rows %d and %d both have id %q; make it a template of the row's params, such as .Params.%s",
+						i, other, row, t.ID, keys[0],
+					)
+				}
+				rows[t.ID] = row
+			}
+			expanded = append(expanded, t)
+		}
+	}
+	return expanded, nil
+}
+
+// renderMatrixIDs renders the id and depends_on of a ticket expanded from a
+// matrix as templates of its params, so each row can have an id of its own,
+// such as "deploy-{{ .Params.service }}", and depend on the same row of
+// another matrix.
+func renderMatrixIDs(ticket *Ticket) error {
+	ctx := map[string]interface{}{"Params": ticket.Params}
+	render := func(text string) (string, error) {
+		tmpl, err := newTemplate("id").Parse(text)
+		if err != nil {
+			return "", err
+		}
+		buf := bytes.NewBufferString("")
+		if err := tmpl.Execute(buf, ctx); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+
+	id, err := render(ticket.ID)
+	if err != nil {
+		return fmt.Errorf("id: %v", err)
+	}
+	ticket.ID = id
+	dependsOn := make([]string, 0, len(ticket.DependsOn))
+	for _, dep := range ticket.DependsOn {
+		dep, err := render(dep)
+		if err != nil {
+			return fmt.Errorf("depends_on: %v", err)
+		}
+		dependsOn = append(dependsOn, dep)
+	}
+	if ticket.DependsOn != nil {
+		ticket.DependsOn = dependsOn
+	}
+	return nil
+}
+
+// matrixFromParams adds the lists named by the ticket's matrix_from, which
+// may have come from a params_file, to its matrix.
+func matrixFromParams(ticket *Ticket) error {
+	if len(ticket.MatrixFrom) == 0 {
+		return nil
+	}
+	matrix := make(map[string][]interface{}, len(ticket.Matrix)+len(ticket.MatrixFrom))
+	for key, values := range ticket.Matrix {
+		matrix[key] = values
+	}
+	for key, param := range ticket.MatrixFrom {
+		values, ok := ticket.Params[param].([]interface{})
+		if !ok {
+			return fmt.Errorf("matrix_from %q: param %q isn't a list", key, param)
+		}
+		matrix[key] = values
+	}
+	ticket.Matrix = matrix
+	return nil
+}
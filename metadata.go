@@ -0,0 +1,206 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+	jira "gopkg.in/andygrunwald/go-jira.v1"
+)
+
+var (
+	metadataCacheTTL = kingpin.Flag(
+		"metadata-cache",
+		"Keep projects, fields, users and teams fetched from JIRA on disk, in the --state-dir, for this long, e.g. 1h.",
+	).Default("0s").Duration()
+)
+
+// prefetcher is implemented by trackers which can fetch what the tickets
+// need up front, rather than one ticket at a time.
+type prefetcher interface {
+	Prefetch(tickets []Ticket) error
+}
+
+// metadataCacheDir is where --metadata-cache keeps metadata, kept apart for
+// each instance.
+func metadataCacheDir() string {
+	sum := sha256.Sum256([]byte((*jiraURL).String()))
+	return path.Join(*stateDir, "metadata", hex.EncodeToString(sum[:])[:12])
+}
+
+// cached fills v from the metadata cache entry called name, if it's younger
+// than --metadata-cache, and otherwise calls fetch to fill it and saves it.
+// fresh skips reading the cache, to replace an entry which is out of date.
+func cached(name string, v interface{}, fresh bool, fetch func() error) error {
+	if *metadataCacheTTL <= 0 {
+		return fetch()
+	}
+
+	p := path.Join(metadataCacheDir(), name+".json")
+	if info, err := os.Stat(p); err == nil && !fresh && time.Since(info.ModTime()) < *metadataCacheTTL {
+		if b, err := ioutil.ReadFile(p); err == nil && json.Unmarshal(b, v) == nil {
+			return nil
+		}
+	}
+
+	if err := fetch(); err != nil {
+		return err
+	}
+	return saveCached(name, v)
+}
+
+// saveCached replaces the metadata cache entry called name with v.
+func saveCached(name string, v interface{}) error {
+	if *metadataCacheTTL <= 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(metadataCacheDir(), 0755); err != nil {
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path.Join(metadataCacheDir(), name+".json"), b, 0644)
+}
+
+// cacheKey makes a user or team name safe to use as a cache entry name.
+func cacheKey(kind string, name string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(name)))
+	return kind + "-" + hex.EncodeToString(sum[:])[:16]
+}
+
+// project returns the project, fetching it on first use. fresh ignores
+// the metadata cache.
+func (t *jiraTracker) project(key string, fresh bool) (*jira.Project, error) {
+	t.mu.Lock()
+	project, ok := t.projects[key]
+	t.mu.Unlock()
+	if ok && !fresh {
+		return project, nil
+	}
+
+	project = new(jira.Project)
+	err := cached("project-"+key, project, fresh, func() error {
+		p, resp, err := t.client.Project.Get(key)
+		if err != nil {
+			return jiraAPIRequestErrorHandler(resp, err)
+		}
+		*project = *p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.projects == nil {
+		t.projects = make(map[string]*jira.Project)
+	}
+	t.projects[key] = project
+	return project, nil
+}
+
+// allFields returns every field on the instance, fetching them on first
+// use.
+func (t *jiraTracker) allFields() ([]jiraField, error) {
+	t.mu.Lock()
+	fields := t.fields
+	t.mu.Unlock()
+	if fields != nil {
+		return fields, nil
+	}
+
+	err := cached("fields", &fields, false, func() error {
+		var err error
+		fields, err = getFields(t.client)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fields = fields
+	return fields, nil
+}
+
+// Prefetch fetches the projects, fields, users and teams the tickets name,
+// all at once, so creating them doesn't wait on each in turn.
+func (t *jiraTracker) Prefetch(tickets []Ticket) error {
+	projects := make(map[string]bool)
+	users := make(map[string]bool)
+	teams := make(map[string]bool)
+	for _, ticket := range tickets {
+		if ticket.Project != "" {
+			projects[ticket.Project] = true
+		}
+		for _, user := range append([]string{ticket.Assignee, ticket.Reporter}, ticket.Watchers...) {
+			if user != "" {
+				users[user] = true
+			}
+		}
+		if ticket.Team != "" {
+			teams[ticket.Team] = true
+		}
+	}
+
+	fetches := make([]func() error, 0, 1+len(projects)+len(users)+len(teams))
+	fetches = append(fetches, func() error {
+		_, err := t.allFields()
+		return err
+	})
+	for key := range projects {
+		key := key
+		fetches = append(fetches, func() error {
+			_, err := t.project(key, false)
+			return err
+		})
+	}
+	for user := range users {
+		user := user
+		fetches = append(fetches, func() error {
+			_, err := t.ResolveUser(user)
+			return err
+		})
+	}
+	for team := range teams {
+		team := team
+		fetches = append(fetches, func() error {
+			_, err := t.teamID(team)
+			return err
+		})
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(fetches))
+	for i, fetch := range fetches {
+		wg.Add(1)
+		go func(i int, fetch func() error) {
+			defer wg.Done()
+			errs[i] = fetch()
+		}(i, fetch)
+	}
+	wg.Wait()
+
+	// Users and teams which can't be found are reported against the
+	// ticket naming them, and projects when they're looked up, so only
+	// the field list has to be fetched.
+	if errs[0] != nil {
+		return fmt.Errorf("fetching fields: %v", errs[0])
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	pushgatewayURL = kingpin.Flag(
+		"pushgateway",
+		"URL of a Prometheus Pushgateway to push the run's metrics to once it finishes.",
+	).String()
+	metricsJob = kingpin.Flag(
+		"metrics-job",
+		"Job name to push metrics under.",
+	).Default("epic-creator").String()
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the API latency
+// histogram's buckets.
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// latencyHistogram is a Prometheus histogram of API request latencies,
+// by method.
+type latencyHistogram struct {
+	mu     sync.Mutex
+	counts map[string][]int
+	sums   map[string]float64
+	totals map[string]int
+	errors map[string]int
+}
+
+var apiLatency = &latencyHistogram{
+	counts: make(map[string][]int),
+	sums:   make(map[string]float64),
+	totals: make(map[string]int),
+	errors: make(map[string]int),
+}
+
+// Observe records a request which took d, and whether it failed.
+func (h *latencyHistogram) Observe(method string, d time.Duration, failed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.counts[method] == nil {
+		h.counts[method] = make([]int, len(latencyBuckets))
+	}
+	seconds := d.Seconds()
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			h.counts[method][i]++
+		}
+	}
+	h.sums[method] += seconds
+	h.totals[method]++
+	if failed {
+		h.errors[method]++
+	}
+}
+
+// WriteTo writes the histogram in the Prometheus text format.
+func (h *latencyHistogram) WriteTo(buf *bytes.Buffer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	methods := make([]string, 0, len(h.totals))
+	for method := range h.totals {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	buf.WriteString("# TYPE epic_creator_api_request_duration_seconds histogram\n")
+	for _, method := range methods {
+		for i, bound := range latencyBuckets {
+			fmt.Fprintf(buf, "epic_creator_api_request_duration_seconds_bucket{method=%q,le=\"%g\"} %d\n", method, bound, h.counts[method][i])
+		}
+		fmt.Fprintf(buf, "epic_creator_api_request_duration_seconds_bucket{method=%q,le=\"+Inf\"} %d\n", method, h.totals[method])
+		fmt.Fprintf(buf, "epic_creator_api_request_duration_seconds_sum{method=%q} %g\n", method, h.sums[method])
+		fmt.Fprintf(buf, "epic_creator_api_request_duration_seconds_count{method=%q} %d\n", method, h.totals[method])
+	}
+	buf.WriteString("# TYPE epic_creator_api_request_errors gauge\n")
+	for _, method := range methods {
+		fmt.Fprintf(buf, "epic_creator_api_request_errors{method=%q} %d\n", method, h.errors[method])
+	}
+}
+
+// timingTransport observes the latency of each request made through it.
+type timingTransport struct {
+	next http.RoundTripper
+}
+
+func newTimingTransport(next http.RoundTripper) *timingTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &timingTransport{next: next}
+}
+
+func (t *timingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	failed := err != nil || resp.StatusCode >= 400
+	apiLatency.Observe(req.Method, time.Since(start), failed)
+	return resp, err
+}
+
+// configureMetrics times the requests made to JIRA and the other backends,
+// if metrics are to be pushed.
+func configureMetrics() {
+	if *pushgatewayURL == "" {
+		return
+	}
+	jiraTransport = newTimingTransport(jiraTransport)
+	http.DefaultClient.Transport = newTimingTransport(http.DefaultClient.Transport)
+}
+
+// pushMetrics pushes the run's metrics to --pushgateway, replacing those of
+// the job's last run.
+func pushMetrics(run *Run, runErr error) error {
+	if *pushgatewayURL == "" {
+		return nil
+	}
+
+	failed := 0
+	if runErr != nil {
+		failed = 1
+	}
+	labels := fmt.Sprintf("{backend=%q}", run.Backend)
+
+	buf := bytes.NewBufferString("")
+	buf.WriteString("# TYPE epic_creator_issues_created gauge\n")
+	fmt.Fprintf(buf, "epic_creator_issues_created%s %d\n", labels, len(run.Created))
+	buf.WriteString("# TYPE epic_creator_run_failed gauge\n")
+	fmt.Fprintf(buf, "epic_creator_run_failed%s %d\n", labels, failed)
+	buf.WriteString("# TYPE epic_creator_run_duration_seconds gauge\n")
+	fmt.Fprintf(buf, "epic_creator_run_duration_seconds%s %g\n", labels, run.Finished.Sub(run.Started).Seconds())
+	buf.WriteString("# TYPE epic_creator_run_finished_timestamp_seconds gauge\n")
+	fmt.Fprintf(buf, "epic_creator_run_finished_timestamp_seconds%s %d\n", labels, run.Finished.Unix())
+	apiLatency.WriteTo(buf)
+
+	url := strings.TrimSuffix(*pushgatewayURL, "/") + "/metrics/job/" + *metricsJob
+	req, err := http.NewRequest("PUT", url, buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("PUT %s: %s", url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,477 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	mockServerCmd = kingpin.Command(
+		"mock-server",
+		"Serve a minimal in-memory JIRA REST API, for demos and for trying tickets files out without an instance.",
+	)
+	mockServerListen = mockServerCmd.Flag(
+		"listen",
+		"Address to listen on.",
+	).Default("localhost:8080").String()
+	mockServerProjects = mockServerCmd.Flag(
+		"project",
+		"Key of a project to serve, each with an epic <key>-1. May be repeated.",
+	).Default("DEMO").Strings()
+)
+
+// mockServerTitle is the server title the mock server gives, by which runs
+// against it know they can do without credentials.
+const mockServerTitle = "epic-creator mock-server"
+
+// isMockJIRA reports whether --jira-url is the mock server, asking it
+// without credentials through transport.
+func isMockJIRA(transport http.RoundTripper) bool {
+	client := &http.Client{Transport: transport, Timeout: 10 * time.Second}
+	resp, err := client.Get(strings.TrimRight((*jiraURL).String(), "/") + "/rest/api/2/serverInfo")
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	var info struct {
+		ServerTitle string `json:"serverTitle"`
+	}
+	return json.NewDecoder(resp.Body).Decode(&info) == nil && info.ServerTitle == mockServerTitle
+}
+
+// mockIssueTypes are the issue types of every mock project. The first is
+// what issues are created as.
+var mockIssueTypes = []string{"Story", "Task", "Epic", "Initiative"}
+
+// mockStatuses are the statuses the mock workflow moves between, with
+// their status categories. Each is also the name of the transition to it.
+var mockStatuses = []struct {
+	name     string
+	category string
+}{
+	{"To Do", "new"},
+	{"In Progress", "indeterminate"},
+	{"Done", "done"},
+}
+
+type mockIssue struct {
+	ID          string
+	Key         string
+	Project     string
+	Type        string
+	Summary     string
+	Description interface{}
+	Status      int
+	Properties  map[string]json.RawMessage
+}
+
+// mockJIRA is the state of the mock server.
+type mockJIRA struct {
+	mu       sync.Mutex
+	projects map[string]int
+	// components are the names of each project's components.
+	components map[string][]string
+	issues     map[string]*mockIssue
+	nextID     int
+}
+
+func newMockJIRA(projects []string) *mockJIRA {
+	m := &mockJIRA{
+		projects:   make(map[string]int),
+		components: make(map[string][]string),
+		issues:     make(map[string]*mockIssue),
+		nextID:     10000,
+	}
+	for _, key := range projects {
+		key = strings.ToUpper(key)
+		m.projects[key] = 0
+		m.create(key, "Epic", "Demo epic", "")
+	}
+	return m
+}
+
+// create adds an issue to the project, which must exist.
+func (m *mockJIRA) create(project string, issueType string, summary string, description interface{}) *mockIssue {
+	m.projects[project]++
+	m.nextID++
+	issue := &mockIssue{
+		ID:          strconv.Itoa(m.nextID),
+		Key:         fmt.Sprintf("%s-%d", project, m.projects[project]),
+		Project:     project,
+		Type:        issueType,
+		Summary:     summary,
+		Description: description,
+	}
+	m.issues[issue.Key] = issue
+	fmt.Printf("Created %s %s: %s\n", issue.Type, issue.Key, issue.Summary)
+	return issue
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeJIRAError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	writeJSON(w, status, map[string]interface{}{
+		"errorMessages": []string{fmt.Sprintf(format, args...)},
+		"errors":        map[string]string{},
+	})
+}
+
+func (m *mockJIRA) projectJSON(r *http.Request, key string) map[string]interface{} {
+	issueTypes := make([]map[string]string, len(mockIssueTypes))
+	for i, name := range mockIssueTypes {
+		issueTypes[i] = map[string]string{"id": strconv.Itoa(10001 + i), "name": name}
+	}
+	components := make([]map[string]string, len(m.components[key]))
+	for i, name := range m.components[key] {
+		components[i] = map[string]string{"id": key + "-" + strconv.Itoa(i+1), "name": name}
+	}
+	return map[string]interface{}{
+		"self":       fmt.Sprintf("http://%s/rest/api/2/project/%s", r.Host, key),
+		"id":         key,
+		"key":        key,
+		"name":       key,
+		"issueTypes": issueTypes,
+		"components": components,
+	}
+}
+
+func (m *mockJIRA) issueJSON(r *http.Request, issue *mockIssue) map[string]interface{} {
+	status := mockStatuses[issue.Status]
+	return map[string]interface{}{
+		"id":   issue.ID,
+		"key":  issue.Key,
+		"self": fmt.Sprintf("http://%s/rest/api/2/issue/%s", r.Host, issue.ID),
+		"fields": map[string]interface{}{
+			"summary":     issue.Summary,
+			"description": issue.Description,
+			"issuetype":   map[string]string{"name": issue.Type},
+			"project":     map[string]string{"key": issue.Project, "id": issue.Project},
+			"status": map[string]interface{}{
+				"name":           status.name,
+				"statusCategory": map[string]string{"key": status.category},
+			},
+		},
+	}
+}
+
+// mockCreateRequest is the body of an issue create request. Only the fields
+// the mock keeps are decoded.
+type mockCreateRequest struct {
+	Fields struct {
+		Project struct {
+			ID  string `json:"id"`
+			Key string `json:"key"`
+		} `json:"project"`
+		IssueType struct {
+			Name string `json:"name"`
+		} `json:"issuetype"`
+		Summary     string      `json:"summary"`
+		Description interface{} `json:"description"`
+	} `json:"fields"`
+}
+
+// createFromRequest creates the issue described by a create request, or
+// returns why it can't.
+func (m *mockJIRA) createFromRequest(req mockCreateRequest) (*mockIssue, error) {
+	project := req.Fields.Project.Key
+	if project == "" {
+		project = req.Fields.Project.ID
+	}
+	if _, ok := m.projects[project]; !ok {
+		return nil, fmt.Errorf("project %q does not exist", project)
+	}
+	if req.Fields.Summary == "" {
+		return nil, fmt.Errorf("summary is required")
+	}
+	issueType := req.Fields.IssueType.Name
+	if issueType == "" {
+		issueType = mockIssueTypes[0]
+	}
+	return m.create(project, issueType, req.Fields.Summary, req.Fields.Description), nil
+}
+
+func (m *mockJIRA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path := strings.Trim(r.URL.Path, "/")
+	fmt.Printf("%s %s\n", r.Method, r.URL.Path)
+
+	if path == "rest/agile/1.0/issue/rank" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	for _, prefix := range []string{"rest/api/2/", "rest/api/3/"} {
+		if strings.HasPrefix(path, prefix) {
+			m.serveAPI(w, r, strings.Split(strings.TrimPrefix(path, prefix), "/"))
+			return
+		}
+	}
+	writeJIRAError(w, http.StatusNotFound, "%s is not part of the mock API", r.URL.Path)
+}
+
+func (m *mockJIRA) serveAPI(w http.ResponseWriter, r *http.Request, parts []string) {
+	route := r.Method + " " + parts[0]
+	if len(parts) > 1 {
+		route += "/*"
+	}
+	if len(parts) > 2 {
+		route += "/" + parts[2]
+	}
+
+	switch route {
+	case "GET serverInfo":
+		writeJSON(w, http.StatusOK, map[string]string{
+			"version":        "8.0.0",
+			"deploymentType": "Server",
+			"serverTitle":    mockServerTitle,
+		})
+	case "GET field":
+		writeJSON(w, http.StatusOK, []map[string]interface{}{
+			{"id": "summary", "name": "Summary", "custom": false},
+			{"id": "customfield_10002", "name": "Story Points", "custom": true, "schema": map[string]string{
+				"type":   "number",
+				"custom": "com.atlassian.jira.plugin.system.customfieldtypes:float",
+			}},
+		})
+	case "GET mypermissions":
+		// The mock lets anyone do anything.
+		perms := make(map[string]interface{})
+		for _, key := range strings.Split(r.URL.Query().Get("permissions"), ",") {
+			perms[key] = map[string]interface{}{"key": key, "havePermission": true}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"permissions": perms})
+	case "GET project/*":
+		key := strings.ToUpper(parts[1])
+		if _, ok := m.projects[key]; !ok {
+			writeJIRAError(w, http.StatusNotFound, "No project could be found with key '%s'.", parts[1])
+			return
+		}
+		writeJSON(w, http.StatusOK, m.projectJSON(r, key))
+	case "POST component":
+		var req jiraComponent
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJIRAError(w, http.StatusBadRequest, "%v", err)
+			return
+		}
+		key := strings.ToUpper(req.Project)
+		if _, ok := m.projects[key]; !ok {
+			writeJIRAError(w, http.StatusBadRequest, "No project could be found with key '%s'.", req.Project)
+			return
+		}
+		m.components[key] = append(m.components[key], req.Name)
+		writeJSON(w, http.StatusCreated, map[string]string{
+			"id":   key + "-" + strconv.Itoa(len(m.components[key])),
+			"name": req.Name,
+		})
+	case "POST filter":
+		writeJSON(w, http.StatusOK, map[string]string{
+			"id":      "10000",
+			"viewUrl": fmt.Sprintf("http://%s/issues/?filter=10000", r.Host),
+		})
+	case "POST issue":
+		var req mockCreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJIRAError(w, http.StatusBadRequest, "%v", err)
+			return
+		}
+		issue, err := m.createFromRequest(req)
+		if err != nil {
+			writeJIRAError(w, http.StatusBadRequest, "%v", err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, m.issueJSON(r, issue))
+	case "POST issue/*":
+		if parts[1] != "bulk" {
+			writeJIRAError(w, http.StatusNotFound, "not found")
+			return
+		}
+		m.serveBulk(w, r)
+	default:
+		m.serveIssue(w, r, route, parts)
+	}
+}
+
+func (m *mockJIRA) serveBulk(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		IssueUpdates []mockCreateRequest `json:"issueUpdates"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJIRAError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	issues := make([]map[string]interface{}, 0, len(body.IssueUpdates))
+	errors := make([]map[string]interface{}, 0)
+	for i, req := range body.IssueUpdates {
+		issue, err := m.createFromRequest(req)
+		if err != nil {
+			errors = append(errors, map[string]interface{}{
+				"status":              http.StatusBadRequest,
+				"failedElementNumber": i,
+				"elementErrors": map[string]interface{}{
+					"errorMessages": []string{err.Error()},
+				},
+			})
+			continue
+		}
+		issues = append(issues, m.issueJSON(r, issue))
+	}
+
+	status := http.StatusCreated
+	if len(errors) > 0 {
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, map[string]interface{}{"issues": issues, "errors": errors})
+}
+
+// serveProperty gets, sets and deletes the issue's properties, for
+// .../issue/<key>/properties/<name>.
+func (m *mockJIRA) serveProperty(w http.ResponseWriter, r *http.Request, issue *mockIssue, parts []string) {
+	if len(parts) < 4 {
+		writeJIRAError(w, http.StatusNotFound, "%s %s is not part of the mock API", r.Method, r.URL.Path)
+		return
+	}
+	name := parts[3]
+	if issue.Properties == nil {
+		issue.Properties = make(map[string]json.RawMessage)
+	}
+
+	switch r.Method {
+	case "GET":
+		value, ok := issue.Properties[name]
+		if !ok {
+			writeJIRAError(w, http.StatusNotFound, "The property with key '%s' does not exist.", name)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"key": name, "value": value})
+	case "PUT":
+		var value json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&value); err != nil {
+			writeJIRAError(w, http.StatusBadRequest, "%v", err)
+			return
+		}
+		issue.Properties[name] = value
+		w.WriteHeader(http.StatusOK)
+	case "DELETE":
+		delete(issue.Properties, name)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// serveIssue serves the requests about a single issue.
+func (m *mockJIRA) serveIssue(w http.ResponseWriter, r *http.Request, route string, parts []string) {
+	if route == "POST issueLink" {
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	if len(parts) < 2 || parts[0] != "issue" {
+		writeJIRAError(w, http.StatusNotFound, "%s is not part of the mock API", r.URL.Path)
+		return
+	}
+
+	if route == "GET issue/*" && parts[1] == "createmeta" {
+		// Nothing the mock's projects have is required.
+		writeJSON(w, http.StatusOK, map[string]interface{}{"projects": []interface{}{}})
+		return
+	}
+	issue := m.issues[strings.ToUpper(parts[1])]
+	if issue == nil {
+		for _, i := range m.issues {
+			if i.ID == parts[1] {
+				issue = i
+			}
+		}
+	}
+	if issue == nil {
+		writeJIRAError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	switch route {
+	case "GET issue/*":
+		writeJSON(w, http.StatusOK, m.issueJSON(r, issue))
+	case "PUT issue/*":
+		var update struct {
+			Fields struct {
+				Description interface{} `json:"description"`
+			} `json:"fields"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			writeJIRAError(w, http.StatusBadRequest, "%v", err)
+			return
+		}
+		if update.Fields.Description != nil {
+			issue.Description = update.Fields.Description
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "DELETE issue/*":
+		delete(m.issues, issue.Key)
+		fmt.Printf("Deleted %s\n", issue.Key)
+		w.WriteHeader(http.StatusNoContent)
+	case "POST issue/*/comment":
+		writeJSON(w, http.StatusCreated, map[string]string{"id": "1"})
+	case "GET issue/*/properties", "PUT issue/*/properties", "DELETE issue/*/properties":
+		m.serveProperty(w, r, issue, parts)
+	case "POST issue/*/worklog":
+		writeJSON(w, http.StatusCreated, map[string]string{"id": "1"})
+	case "POST issue/*/remotelink":
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"id": 1})
+	case "POST issue/*/watchers":
+		w.WriteHeader(http.StatusNoContent)
+	case "POST issue/*/attachments":
+		writeJSON(w, http.StatusOK, []interface{}{})
+	case "GET issue/*/transitions":
+		transitions := make([]map[string]interface{}, len(mockStatuses))
+		for i, status := range mockStatuses {
+			transitions[i] = map[string]interface{}{"id": strconv.Itoa(i), "name": status.name}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"transitions": transitions})
+	case "POST issue/*/transitions":
+		var body struct {
+			Transition struct {
+				ID string `json:"id"`
+			} `json:"transition"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		i, err := strconv.Atoi(body.Transition.ID)
+		if err != nil || i < 0 || i >= len(mockStatuses) {
+			writeJIRAError(w, http.StatusBadRequest, "no transition %q", body.Transition.ID)
+			return
+		}
+		issue.Status = i
+		fmt.Printf("Moved %s to %s\n", issue.Key, mockStatuses[i].name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeJIRAError(w, http.StatusNotFound, "%s %s is not part of the mock API", r.Method, r.URL.Path)
+	}
+}
+
+func runMockServer() {
+	m := newMockJIRA(*mockServerProjects)
+	epics := make([]string, 0, len(m.issues))
+	for key := range m.issues {
+		epics = append(epics, key)
+	}
+	sort.Strings(epics)
+
+	fmt.Printf("Serving a mock JIRA on http://%s with epics %s.\n", *mockServerListen, strings.Join(epics, ", "))
+	fmt.Printf("Try: epic-creator --jira-url http://%s %s\n", *mockServerListen, epics[0])
+	if err := http.ListenAndServe(*mockServerListen, m); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	moveCmd = kingpin.Command(
+		"move",
+		"Move issues from one epic to another, for when epics are split or merged.",
+	)
+	moveTo = moveCmd.Flag(
+		"to",
+		"Key of the epic to move the issues to.",
+	).Required().String()
+	moveFrom = moveCmd.Flag(
+		"from",
+		"Key of an epic to move issues out of. Every issue in it is moved, unless narrowed down with --jql.",
+	).String()
+	moveJQL = moveCmd.Flag(
+		"jql",
+		"JQL selecting the issues to move, within --from if given.",
+	).String()
+	moveKeys = moveCmd.Arg("keys", "Keys of issues to move.").Strings()
+)
+
+// issueSearcher is implemented by trackers which can search for issues
+// with a query in their own language.
+type issueSearcher interface {
+	SearchIssues(query string) ([]IssueStatus, error)
+}
+
+// epicMover is implemented by trackers which can move existing issues into
+// another epic.
+type epicMover interface {
+	MoveToEpic(key string, epic *Epic) error
+}
+
+// issuesToMove returns the keys of the issues the move command names.
+func issuesToMove(tracker Tracker) ([]string, error) {
+	keys := append([]string{}, *moveKeys...)
+	if *moveFrom == "" && *moveJQL == "" {
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("name the issues to move, with keys, --from or --jql")
+		}
+		return keys, nil
+	}
+
+	var found []IssueStatus
+	var err error
+	if *moveFrom != "" {
+		lister, ok := tracker.(epicLister)
+		if !ok {
+			return nil, fmt.Errorf("backend %s does not support listing an epic's issues", *backend)
+		}
+		from, err := tracker.ResolveEpic(*moveFrom)
+		if err != nil {
+			return nil, err
+		}
+		found, err = lister.EpicIssues(from, *moveJQL)
+	} else {
+		searcher, ok := tracker.(issueSearcher)
+		if !ok {
+			return nil, fmt.Errorf("backend %s does not support searching for issues", *backend)
+		}
+		found, err = searcher.SearchIssues(*moveJQL)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range found {
+		keys = append(keys, issue.Key)
+	}
+	return keys, nil
+}
+
+func runMove() {
+	tracker, err := newTracker(*backend)
+	if err != nil {
+		panic(err)
+	}
+	mover, ok := tracker.(epicMover)
+	if !ok {
+		panic(fmt.Errorf("backend %s does not support moving issues between epics", *backend))
+	}
+
+	to, err := tracker.ResolveEpic(*moveTo)
+	if err != nil {
+		panic(err)
+	}
+	keys, err := issuesToMove(tracker)
+	if err != nil {
+		panic(err)
+	}
+
+	for i, key := range keys {
+		if err := mover.MoveToEpic(key, to); err != nil {
+			fmt.Printf("Stopped after moving %d of %d issue(s).\n", i, len(keys))
+			panic(err)
+		}
+		fmt.Printf("Moved %s to %s\n", key, to.Key)
+	}
+	fmt.Printf("Moved %d issue(s) to %s.\n", len(keys), to.Key)
+}
+
+// MoveToEpic sets the issue's parent on Cloud, and its Epic Link through
+// the agile API elsewhere.
+func (t *jiraTracker) MoveToEpic(key string, epic *Epic) error {
+	if jiraCloud {
+		return t.updateIssue(key, map[string]interface{}{
+			"fields": map[string]interface{}{
+				"parent": map[string]string{"key": epic.Key},
+			},
+		})
+	}
+
+	body := map[string][]string{"issues": {key}}
+	req, err := t.client.NewRequest("POST", "rest/agile/1.0/epic/"+epic.Key+"/issue", body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Do(req, nil)
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+	"gopkg.in/jcmturner/gokrb5.v7/client"
+	"gopkg.in/jcmturner/gokrb5.v7/config"
+	"gopkg.in/jcmturner/gokrb5.v7/credentials"
+	"gopkg.in/jcmturner/gokrb5.v7/spnego"
+)
+
+var (
+	jiraAuth = kingpin.Flag(
+		"jira-auth",
+		"How to log into JIRA: basic, with the credentials on every request; session, with the credentials once, for a session cookie; or negotiate, with Kerberos (SPNEGO), for Data Center behind corporate SSO.",
+	).Default("basic").Enum("basic", "session", "negotiate")
+	negotiateCommand = kingpin.Flag(
+		"negotiate-command",
+		"Shell command which prints a base64 SPNEGO token for the service principal in $EPIC_CREATOR_SPN, e.g. HTTP@jira.example.com, instead of making one from the Kerberos ticket cache. For --jira-auth negotiate.",
+	).String()
+)
+
+// negotiateTransport logs into JIRA with SPNEGO. The token is only sent
+// until JIRA sets a session cookie, which the client's jar sends from then
+// on, or when JIRA turns the session down.
+type negotiateTransport struct {
+	next http.RoundTripper
+	// krb makes the tokens, from the ticket cache, unless
+	// --negotiate-command is given.
+	krb *client.Client
+}
+
+// kerberosClient returns a Kerberos client with the tickets in the cache
+// kinit writes to: $KRB5CCNAME, or /tmp/krb5cc_<uid>, configured by
+// $KRB5_CONFIG or /etc/krb5.conf.
+func kerberosClient() (*client.Client, error) {
+	confPath := os.Getenv("KRB5_CONFIG")
+	if confPath == "" {
+		confPath = "/etc/krb5.conf"
+	}
+	conf, err := config.Load(confPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %v", confPath, err)
+	}
+
+	cachePath := strings.TrimPrefix(os.Getenv("KRB5CCNAME"), "FILE:")
+	if cachePath == "" {
+		cachePath = fmt.Sprintf("/tmp/krb5cc_%d", os.Getuid())
+	}
+	cache, err := credentials.LoadCCache(cachePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading the Kerberos ticket cache %s, which kinit writes: %v", cachePath, err)
+	}
+	return client.NewClientFromCCache(cache, conf)
+}
+
+// negotiateToken runs --negotiate-command for a token for the host's HTTP
+// service principal. Kerberos rejects replayed tokens, so each request
+// needs a fresh one.
+func negotiateToken(host string) (string, error) {
+	var out bytes.Buffer
+	cmd := exec.Command("sh", "-c", *negotiateCommand)
+	cmd.Env = append(os.Environ(), "EPIC_CREATOR_SPN=HTTP@"+host)
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("getting a SPNEGO token for %s: %v", host, err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+func (t *negotiateTransport) withToken(req *http.Request) (*http.Request, error) {
+	authed := req.Clone(req.Context())
+	if t.krb != nil {
+		if err := spnego.SetSPNEGOHeader(t.krb, authed, "HTTP/"+req.URL.Hostname()); err != nil {
+			return nil, fmt.Errorf("getting a SPNEGO token for %s: %v", req.URL.Hostname(), err)
+		}
+		return authed, nil
+	}
+
+	token, err := negotiateToken(req.URL.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	authed.Header.Set("Authorization", "Negotiate "+token)
+	return authed, nil
+}
+
+func (t *negotiateTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Cookie") == "" {
+		authed, err := t.withToken(req)
+		if err != nil {
+			return nil, err
+		}
+		return t.next.RoundTrip(authed)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if !strings.Contains(resp.Header.Get("WWW-Authenticate"), "Negotiate") {
+		return resp, nil
+	}
+	// The session expired. Negotiate again, if the body can be sent again.
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+	authed, err := t.withToken(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetBody != nil {
+		if authed.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return t.next.RoundTrip(authed)
+}
+
+// negotiateClient returns an HTTP client which logs into JIRA with SPNEGO
+// and keeps the session it's given, making requests through transport.
+func negotiateClient(transport http.RoundTripper) (*http.Client, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	negotiate := &negotiateTransport{next: transport}
+	if *negotiateCommand == "" {
+		if negotiate.krb, err = kerberosClient(); err != nil {
+			return nil, err
+		}
+	}
+	return &http.Client{
+		Transport: negotiate,
+		Jar:       jar,
+	}, nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// backendURL is the URL of the instance the backend talks to, whose host is
+// looked up in ~/.netrc.
+func backendURL() string {
+	switch *backend {
+	case "jira":
+		if *jiraURL != nil {
+			return (*jiraURL).String()
+		}
+	case "github":
+		return *githubURL
+	case "gitlab":
+		return *gitlabURL
+	case "azure":
+		return *adoURL
+	case "linear":
+		return *linearURL
+	}
+	return ""
+}
+
+// netrcPath is $NETRC, or ~/.netrc.
+func netrcPath() string {
+	if p := os.Getenv("NETRC"); p != "" {
+		return p
+	}
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(u.HomeDir, ".netrc")
+}
+
+// netrcCreds returns the login and password for the backend's host from
+// the netrc file, falling back to its default entry, or nil if it has
+// neither or there is no netrc file.
+func netrcCreds() (*Creds, error) {
+	u, err := url.Parse(backendURL())
+	if err != nil || u.Hostname() == "" {
+		return nil, nil
+	}
+	p := netrcPath()
+	if p == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(p)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseNetrc(string(data), u.Hostname()), nil
+}
+
+// parseNetrc finds the entry for host in the contents of a netrc file, or
+// the default entry if there's none for it.
+func parseNetrc(data string, host string) *Creds {
+	var found, fallback, current *Creds
+	lines := strings.Split(data, "\n")
+	for i := 0; i < len(lines); i++ {
+		fields := strings.Fields(lines[i])
+		for j := 0; j < len(fields); j++ {
+			next := func() string {
+				if j+1 < len(fields) {
+					j++
+					return fields[j]
+				}
+				return ""
+			}
+			switch fields[j] {
+			case "machine":
+				current = nil
+				if next() == host && found == nil {
+					found = &Creds{}
+					current = found
+				}
+			case "default":
+				current = nil
+				if fallback == nil {
+					fallback = &Creds{}
+					current = fallback
+				}
+			case "login":
+				if login := next(); current != nil {
+					current.User = login
+				}
+			case "password":
+				if password := next(); current != nil {
+					current.Password = password
+				}
+			case "macdef":
+				// A macro runs to the next blank line.
+				for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+					i++
+				}
+				j = len(fields)
+			}
+		}
+	}
+	if found != nil {
+		return found
+	}
+	return fallback
+}
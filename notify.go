@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// epicLink returns a link to the epic for people, rather than the API.
+func epicLink(epic *Epic) string {
+	if *backend == "jira" && *jiraURL != nil {
+		return strings.TrimSuffix((*jiraURL).String(), "/") + "/browse/" + epic.Key
+	}
+	return epic.URL
+}
+
+// notificationText summarizes a finished run in a line or two of Markdown,
+// which both Slack and Teams render.
+func notificationText(epic *Epic, run *Run, runErr error) string {
+	link := epic.Key
+	if url := epicLink(epic); url != "" {
+		link = fmt.Sprintf("<%s|%s>", url, epic.Key)
+		if *notifyFormat == "teams" {
+			link = fmt.Sprintf("[%s](%s)", epic.Key, url)
+		}
+	}
+
+	text := fmt.Sprintf("epic-creator created %d issue(s) in %s (run %s).", len(run.Created), link, run.ID)
+	if epic.Key == "" {
+		text = fmt.Sprintf("epic-creator created %d issue(s) (run %s).", len(run.Created), run.ID)
+	}
+	if runErr != nil {
+		text += fmt.Sprintf("\nThe run failed: %v", runErr)
+	}
+	return text
+}
+
+func postJSON(url string, body interface{}, header http.Header) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return postBody(url, data, header)
+}
+
+// postBody POSTs JSON data to url, failing on a non-2xx response.
+func postBody(url string, data []byte, header http.Header) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("POST %s: %s: %s", url, resp.Status, respBody)
+	}
+	return nil
+}
+
+// notify posts a summary of the run to --notify-webhook, in the format of
+// --notify-format.
+func notify(epic *Epic, run *Run, runErr error) error {
+	text := notificationText(epic, run, runErr)
+
+	var payload interface{}
+	switch *notifyFormat {
+	case "teams":
+		color := "2EB886"
+		if runErr != nil {
+			color = "D00000"
+		}
+		payload = map[string]string{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    "epic-creator run " + run.ID,
+			"themeColor": color,
+			"text":       text,
+		}
+	default:
+		payload = map[string]string{"text": text}
+	}
+	return postJSON(*notifyWebhook, payload, nil)
+}
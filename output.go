@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	noColor = kingpin.Flag(
+		"no-color",
+		"Don't color output. Color is also left off when stdout isn't a terminal, or NO_COLOR is set.",
+	).Bool()
+	quiet = createCmd.Flag(
+		"quiet",
+		"Print only the keys of the created issues, one per line.",
+	).Bool()
+)
+
+const (
+	colorRed   = "31"
+	colorGreen = "32"
+	colorDim   = "2"
+)
+
+// createdSummaryWidth is how much of a created issue's summary is printed.
+const createdSummaryWidth = 60
+
+// stdout is where created issues are printed. --quiet points os.Stdout
+// elsewhere, so everything but the created keys is dropped.
+var stdout = os.Stdout
+
+// silenceStdout drops everything printed to os.Stdout from here on.
+func silenceStdout() error {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	os.Stdout = devNull
+	return nil
+}
+
+// colorEnabled reports whether f is a terminal which output should be
+// colored on.
+func colorEnabled(f *os.File) bool {
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colored wraps s in the ANSI color code, if f is to be colored.
+func colored(f *os.File, code string, s string) string {
+	if !colorEnabled(f) {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+// printCreated prints a line for a created issue: its key, its summary and,
+// if there is one, a link to it. With --quiet, only the key is printed.
+func printCreated(key string, summary string, link string) {
+	if *quiet {
+		fmt.Fprintln(stdout, key)
+		return
+	}
+	summary, _ = truncate(summary, createdSummaryWidth)
+	line := fmt.Sprintf("%s %-12s %s", colored(stdout, colorGreen, "✓"), key, summary)
+	if link != "" {
+		line += "  " + colored(stdout, colorDim, link)
+	}
+	fmt.Fprintln(stdout, line)
+}
+
+// printFailed prints why the run failed.
+func printFailed(err error) {
+	fmt.Fprintf(os.Stderr, "%s %v\n", colored(os.Stderr, colorRed, "✗"), err)
+}
+
+// runFailure is what runCreate panics with when the run fails, once it has
+// reported the failure everywhere it should.
+type runFailure struct {
+	err error
+}
+
+func (f runFailure) Error() string {
+	return f.err.Error()
+}
+
+// exitOnRunFailure, deferred in main, prints a failed run's error and exits
+// non-zero, rather than panicking with a stack trace. Other panics go on.
+func exitOnRunFailure() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if f, ok := r.(runFailure); ok {
+		printFailed(f.err)
+		os.Exit(1)
+	}
+	panic(r)
+}
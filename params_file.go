@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// paramsFileCommand returns the command which evaluates a params file to
+// JSON, or nil if the file is JSON already. YAML is read with CUE, which
+// exports YAML files as they are.
+func paramsFileCommand(p string) *exec.Cmd {
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".yaml", ".yml":
+		return exec.Command(*cueCommand, "export", "--out", "json", p)
+	}
+	return evaluatedTicketFile(p)
+}
+
+// loadParamsFile reads the params held in a JSON, YAML, Jsonnet or CUE file,
+// or served as JSON at an HTTP(S) URL.
+func loadParamsFile(p string) (map[string]interface{}, error) {
+	if isURL(p) {
+		return fetchParams(p)
+	}
+	var data []byte
+	if cmd := paramsFileCommand(p); cmd != nil {
+		out, err := evaluateTicketFile(cmd, p)
+		if err != nil {
+			return nil, err
+		}
+		data = out.Bytes()
+	} else {
+		var err error
+		data, err = ioutil.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var params map[string]interface{}
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, fmt.Errorf("%s: %v", p, err)
+	}
+	return params, nil
+}
+
+// mergeParamsFile adds the params from the ticket's params file, relative to
+// dir unless it's a URL, under its own params, which take precedence.
+func mergeParamsFile(ticket *Ticket, dir string) error {
+	if ticket.ParamsFile == "" {
+		return nil
+	}
+	p := ticket.ParamsFile
+	if !isURL(p) && !filepath.IsAbs(p) {
+		p = filepath.Join(dir, p)
+	}
+	params, err := loadParamsFile(p)
+	if err != nil {
+		return err
+	}
+	for k, v := range ticket.Params {
+		params[k] = v
+	}
+	ticket.Params = params
+	return nil
+}
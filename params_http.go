@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	paramsHeaders = createCmd.Flag(
+		"params-header",
+		"Header to send when fetching params_file URLs from a host or URL prefix, as \"<host or prefix>=Name: value\", e.g. for auth. $VARS in the value are expanded from the environment. May be repeated.",
+	).Strings()
+)
+
+// paramsTimeout is how long fetching a params_file URL may take.
+const paramsTimeout = 30 * time.Second
+
+// fetchedParams caches params_file URLs by URL, so tickets sharing one only
+// fetch it once.
+var fetchedParams = make(map[string]map[string]interface{})
+
+// isURL reports whether a params file is an HTTP(S) URL rather than a path.
+func isURL(p string) bool {
+	return strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://")
+}
+
+// fetchParams fetches the JSON object of params served at a URL, sending
+// the --params-header headers.
+func fetchParams(url string) (map[string]interface{}, error) {
+	if params, ok := fetchedParams[url]; ok {
+		return copyParams(params), nil
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	for _, header := range *paramsHeaders {
+		scope, name, value, err := parseParamsHeader(header)
+		if err != nil {
+			return nil, err
+		}
+		if headerApplies(scope, req.URL) {
+			req.Header.Set(name, os.ExpandEnv(value))
+		}
+	}
+
+	client := &http.Client{
+		Timeout: paramsTimeout,
+		// Headers are sent on redirects, so don't follow one off the host
+		// they were meant for.
+		CheckRedirect: func(redirect *http.Request, via []*http.Request) error {
+			if !strings.EqualFold(redirect.URL.Host, via[0].URL.Host) {
+				return fmt.Errorf("%s redirected to another host, %s", url, redirect.URL.Host)
+			}
+			if len(via) >= 10 {
+				return fmt.Errorf("%s redirected too many times", url)
+			}
+			return nil
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+
+	var params map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&params); err != nil {
+		return nil, fmt.Errorf("%s: %v", url, err)
+	}
+	fetchedParams[url] = params
+	return copyParams(params), nil
+}
+
+// parseParamsHeader splits a --params-header into the host or URL prefix
+// it's sent to, and the header's name and value.
+func parseParamsHeader(header string) (scope string, name string, value string, err error) {
+	parts := strings.SplitN(header, "=", 2)
+	if len(parts) == 2 {
+		scope = parts[0]
+		if !strings.Contains(scope, "://") && !isHostScope(scope) {
+			// The = is in the header, which was given no scope.
+			scope = ""
+		}
+	}
+	if scope == "" {
+		return "", "", "", fmt.Errorf("--params-header %q isn't <host or prefix>=Name: value", header)
+	}
+	nameValue := strings.SplitN(parts[1], ":", 2)
+	if len(nameValue) != 2 || strings.TrimSpace(nameValue[0]) == "" {
+		return "", "", "", fmt.Errorf("--params-header %q isn't <host or prefix>=Name: value", header)
+	}
+	return scope, strings.TrimSpace(nameValue[0]), strings.TrimSpace(nameValue[1]), nil
+}
+
+// isHostScope reports whether scope is a host, with or without a port, and
+// so not the start of a "Name: value" header.
+func isHostScope(scope string) bool {
+	if strings.Contains(scope, " ") {
+		return false
+	}
+	i := strings.LastIndex(scope, ":")
+	if i == -1 {
+		return true
+	}
+	port := scope[i+1:]
+	if port == "" {
+		return false
+	}
+	for _, r := range port {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// headerApplies reports whether a header scoped to a host or URL prefix is
+// to be sent to u.
+func headerApplies(scope string, u *neturl.URL) bool {
+	if strings.Contains(scope, "://") {
+		s := u.String()
+		if !strings.HasPrefix(s, scope) {
+			return false
+		}
+		// https://example.com mustn't match https://example.com.evil.
+		rest := s[len(scope):]
+		return rest == "" || strings.HasSuffix(scope, "/") || strings.ContainsAny(rest[:1], "/?#")
+	}
+	return strings.EqualFold(u.Host, scope) || strings.EqualFold(u.Hostname(), scope)
+}
+
+// copyParams returns a shallow copy of params, for a ticket to merge its own
+// into.
+func copyParams(params map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	neturl "net/url"
+	"testing"
+)
+
+func TestParseParamsHeader(t *testing.T) {
+	tests := []struct {
+		header string
+		scope  string
+		name   string
+		value  string
+	}{
+		{"catalog.example.com=Authorization: Bearer abc", "catalog.example.com", "Authorization", "Bearer abc"},
+		{"https://catalog.example.com/api=X-Key: a=b", "https://catalog.example.com/api", "X-Key", "a=b"},
+		{"localhost:8080=X-Key:abc", "localhost:8080", "X-Key", "abc"},
+	}
+	for _, test := range tests {
+		scope, name, value, err := parseParamsHeader(test.header)
+		if err != nil {
+			t.Errorf("%q: %v", test.header, err)
+			continue
+		}
+		if scope != test.scope || name != test.name || value != test.value {
+			t.Errorf(
+				"%q: got %q, %q, %q, want %q, %q, %q",
+				test.header, scope, name, value, test.scope, test.name, test.value,
+			)
+		}
+	}
+}
+
+func TestParseParamsHeaderErrors(t *testing.T) {
+	tests := []string{
+		"Authorization: Bearer abc",
+		"X-Key: a=b",
+		"X-Key:a=b",
+		"localhost:=X-Key: abc",
+		"catalog.example.com=Authorization",
+		"catalog.example.com=: abc",
+		"=X-Key: abc",
+	}
+	for _, header := range tests {
+		if _, _, _, err := parseParamsHeader(header); err == nil {
+			t.Errorf("%q: expected an error", header)
+		}
+	}
+}
+
+func TestHeaderApplies(t *testing.T) {
+	tests := []struct {
+		scope string
+		url   string
+		want  bool
+	}{
+		{"catalog.example.com", "https://catalog.example.com/api/items", true},
+		{"CATALOG.example.com", "https://catalog.example.com/", true},
+		{"catalog.example.com", "https://catalog.example.com:8443/", true},
+		{"catalog.example.com:8443", "https://catalog.example.com:8443/", true},
+		{"catalog.example.com:8443", "https://catalog.example.com/", false},
+		{"catalog.example.com", "https://other.example.com/", false},
+		{"catalog.example.com", "https://catalog.example.com.evil/", false},
+		{"https://catalog.example.com", "https://catalog.example.com", true},
+		{"https://catalog.example.com", "https://catalog.example.com.evil/", false},
+		{"https://catalog.example.com/api", "https://catalog.example.com/api", true},
+		{"https://catalog.example.com/api", "https://catalog.example.com/api/items", true},
+		{"https://catalog.example.com/api", "https://catalog.example.com/api?q=1", true},
+		{"https://catalog.example.com/api", "https://catalog.example.com/apix", false},
+		{"https://catalog.example.com/api/", "https://catalog.example.com/api/items", true},
+		{"https://catalog.example.com/api", "https://catalog.example.com/other", false},
+		{"https://catalog.example.com/api", "http://catalog.example.com/api", false},
+	}
+	for _, test := range tests {
+		u, err := neturl.Parse(test.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := headerApplies(test.scope, u); got != test.want {
+			t.Errorf("%q applied to %s: got %v, want %v", test.scope, test.url, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// paramRefs are the params templates refer to, as .Params.name or
+// index .Params "name". all is set if a template uses .Params in some other
+// way, such as ranging over it, so any param may be in use.
+type paramRefs struct {
+	names map[string]bool
+	all   bool
+}
+
+func newParamRefs() *paramRefs {
+	return &paramRefs{names: make(map[string]bool)}
+}
+
+// addTemplate adds the params the template, and those it defines, refer to.
+func (r *paramRefs) addTemplate(tmpl *template.Template) {
+	if tmpl == nil {
+		return
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			r.walk(t.Tree.Root)
+		}
+	}
+}
+
+// addText parses text, such as a ticket's comment or when, as a template
+// and adds the params it refers to.
+func (r *paramRefs) addText(name string, text string) {
+	if text == "" {
+		return
+	}
+	tmpl, err := newTemplate(name).Parse(text)
+	if err == nil {
+		r.addTemplate(tmpl)
+	}
+}
+
+func (r *paramRefs) walk(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			r.walk(child)
+		}
+	case *parse.ActionNode:
+		r.walk(n.Pipe)
+	case *parse.IfNode:
+		r.walkBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		r.walkBranch(&n.BranchNode)
+	case *parse.WithNode:
+		r.walkBranch(&n.BranchNode)
+	case *parse.TemplateNode:
+		r.walk(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			r.walk(cmd)
+		}
+	case *parse.CommandNode:
+		// index .Params "name"
+		if len(n.Args) >= 3 {
+			fn, isIdent := n.Args[0].(*parse.IdentifierNode)
+			field, isField := n.Args[1].(*parse.FieldNode)
+			name, isString := n.Args[2].(*parse.StringNode)
+			if isIdent && fn.Ident == "index" && isField && isString && strings.Join(field.Ident, ".") == "Params" {
+				r.names[name.Text] = true
+				return
+			}
+		}
+		for _, arg := range n.Args {
+			r.walk(arg)
+		}
+	case *parse.FieldNode:
+		r.addIdent(n.Ident)
+	case *parse.VariableNode:
+		// $.Params.name
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			r.addIdent(n.Ident[1:])
+		}
+	case *parse.ChainNode:
+		r.walk(n.Node)
+	}
+}
+
+func (r *paramRefs) walkBranch(n *parse.BranchNode) {
+	r.walk(n.Pipe)
+	r.walk(n.List)
+	r.walk(n.ElseList)
+}
+
+func (r *paramRefs) addIdent(ident []string) {
+	if len(ident) == 0 || ident[0] != "Params" {
+		return
+	}
+	if len(ident) == 1 {
+		r.all = true
+		return
+	}
+	r.names[ident[1]] = true
+}
+
+// reportParamUsage prints the params tickets have which no template refers
+// to, and those templates refer to which tickets don't have, to catch typos
+// before anything is created.
+func reportParamUsage(templates []*template.Template, issues []Issue) {
+	refs := newParamRefs()
+	for _, tmpl := range templates {
+		refs.addTemplate(tmpl)
+	}
+	for _, issue := range issues {
+		refs.addText("comment", issue.Ticket.Comment)
+		refs.addText("when", issue.Ticket.When)
+	}
+
+	unused := make(map[string][]int)
+	missing := make(map[string][]int)
+	for _, issue := range issues {
+		for name := range issue.Ticket.Params {
+			// The epic param is set on every ticket.
+			if !refs.all && !refs.names[name] && name != "epic" {
+				unused[name] = append(unused[name], issue.Index)
+			}
+		}
+		for name := range refs.names {
+			if _, ok := issue.Ticket.Params[name]; !ok {
+				missing[name] = append(missing[name], issue.Index)
+			}
+		}
+	}
+
+	printParamReport("Params no template uses", unused)
+	printParamReport("Params the templates use which tickets don't have", missing)
+}
+
+func printParamReport(heading string, params map[string][]int) {
+	if len(params) == 0 {
+		return
+	}
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("%s:\n", heading)
+	for _, name := range names {
+		tickets := make([]string, len(params[name]))
+		for i, index := range params[name] {
+			tickets[i] = fmt.Sprint(index)
+		}
+		fmt.Printf("  %s (ticket(s) %s)\n", name, strings.Join(tickets, ", "))
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Permissions needed in every project a run creates issues in: to create
+// them, then to edit and link them once they're created.
+const (
+	permCreateIssues = "CREATE_ISSUES"
+	permEditIssues   = "EDIT_ISSUES"
+	permLinkIssues   = "LINK_ISSUES"
+)
+
+// permissionNames are how JIRA's permission schemes name the permissions.
+var permissionNames = map[string]string{
+	permCreateIssues:     "Create Issues",
+	permEditIssues:       "Edit Issues",
+	permLinkIssues:       "Link Issues",
+	permModifyReporter:   "Modify Reporter",
+	permSetIssueSecurity: "Set Issue Security",
+}
+
+// permissionChecker is a Tracker which can check, before anything is
+// created, that the caller may do all a run does in each project.
+type permissionChecker interface {
+	CheckPermissions(issues []Issue) error
+}
+
+// checkRunPermissions fails if the caller is missing a permission the run
+// needs, on backends which can tell.
+func checkRunPermissions(tracker Tracker, issues []Issue) error {
+	checker, ok := tracker.(permissionChecker)
+	if !ok || len(issues) == 0 {
+		return nil
+	}
+	return checker.CheckPermissions(issues)
+}
+
+// CheckPermissions checks each project the issues are in for the
+// permissions to create, edit and link issues, and for those their tickets'
+// fields need, naming every one which is missing.
+func (t *jiraTracker) CheckPermissions(issues []Issue) error {
+	needed := make(map[string]map[string]string)
+	for _, issue := range issues {
+		project := issue.Ticket.Project
+		perms, ok := needed[project]
+		if !ok {
+			perms = map[string]string{
+				permCreateIssues: "creating issues",
+				permEditIssues:   "updating the created issues",
+				permLinkIssues:   "linking the created issues",
+			}
+			needed[project] = perms
+		}
+		for key, why := range requiredPermissions(issue.Ticket) {
+			perms[key] = why
+		}
+	}
+
+	projects := make([]string, 0, len(needed))
+	for project := range needed {
+		projects = append(projects, project)
+	}
+	sort.Strings(projects)
+	missing := make([]string, 0)
+	for _, project := range projects {
+		lacking, err := missingPermissions(t.client, project, needed[project])
+		if err != nil {
+			return err
+		}
+		missing = append(missing, lacking...)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf(
+			"you're missing permissions the run needs; ask an administrator of each project to grant them:\n%s",
+			strings.Join(missing, "\n"),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+var (
+	defaultProject = createCmd.Flag(
+		"project",
+		"Project for tickets which don't name one. Defaults to the project of the ticket's epic.",
+	).String()
+	dryRun = createCmd.Flag(
+		"dry-run",
+		"Render every ticket and print what would be created, without creating anything.",
+	).Bool()
+)
+
+// ticketProject returns the project the ticket is to be created in: its
+// own, else --project, else its epic's. inferred is set if the ticket didn't
+// name one.
+func ticketProject(ticket Ticket, epic *Epic) (project string, inferred bool, err error) {
+	if ticket.Project != "" {
+		return ticket.Project, false, nil
+	}
+	if *defaultProject != "" {
+		return *defaultProject, true, nil
+	}
+	if epic.Project != "" {
+		return epic.Project, true, nil
+	}
+	return "", false, fmt.Errorf("no project, and neither --project nor the epic %s gives one", epic.Key)
+}
+
+// planHierarchy stands in for creating the hierarchy in a dry run, adding a
+// placeholder for each epic so tickets can still name them.
+func planHierarchy(h *hierarchy, epics *epicResolver) error {
+	ctx := newHierarchyContext(h, time.Now().UTC())
+	if h.Initiative != nil {
+		node, err := h.Initiative.render(ctx)
+		if err != nil {
+			return fmt.Errorf("initiative: %v", err)
+		}
+		fmt.Printf("Would create initiative in %s: %s\n", node.Project, node.Summary)
+	}
+	for _, node := range h.Epics {
+		node, err := node.render(ctx)
+		if err != nil {
+			return fmt.Errorf("epic %q: %v", node.Name, err)
+		}
+		fmt.Printf("Would create epic %q in %s: %s\n", node.Name, node.Project, node.Summary)
+		epics.Add(node.Name, &Epic{Key: node.Name, Project: node.Project})
+	}
+	return nil
+}
+
+// printPlan prints the issues a dry run would create. inferred holds the
+// indexes of tickets whose project was inferred.
+func printPlan(issues []Issue, inferred map[int]bool) {
+	for _, issue := range issues {
+		project := issue.Ticket.Project
+		if inferred[issue.Index] {
+			project += " (inferred)"
+		}
+		fmt.Printf("Would create ticket %d in %s, epic %s: %s\n", issue.Index, project, issue.Epic.Key, issue.Summary)
+	}
+	fmt.Printf("Would create %d issue(s).\n", len(issues))
+}
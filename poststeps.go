@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+)
+
+// postCreateStep is run for each issue once every issue in a run has been
+// created, along with the rendered issue it was created from.
+type postCreateStep func(tracker Tracker, run *Run, issue Issue, created CreatedIssue) error
+
+// postCreateSteps are run, in order, for every created issue.
+var postCreateSteps = []postCreateStep{
+	tagWithRun,
+	attachFiles,
+	addWatchers,
+	addRemoteLinks,
+	logWork,
+	postComment,
+	transitionCreated,
+	postCreateHook,
+}
+
+func runPostCreateSteps(tracker Tracker, issues []Issue, run *Run) error {
+	byIndex := indexIssues(issues)
+	for _, created := range run.Created {
+		issue, ok := byIndex[created.Ticket]
+		if !ok {
+			// Epics created from --hierarchy have no ticket.
+			continue
+		}
+		for _, step := range postCreateSteps {
+			if err := step(tracker, run, issue, created); err != nil {
+				return fmt.Errorf("%s: %v", created.Key, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+var (
+	projectConfigPath = createCmd.Flag(
+		"project-config",
+		"Path to a JSON object of settings by project key: issue_type, epic_field, components and field_defaults.",
+	).ExistingFile()
+)
+
+// projectConfig is what --project-config knows about a project, so tickets
+// don't each have to.
+type projectConfig struct {
+	// IssueType is the issue type to create the project's tickets as,
+	// instead of Task or Story.
+	IssueType string `json:"issue_type,omitempty"`
+	// EpicField is the ID of the custom field holding the epic, for
+	// tickets which don't give a custom_epic_field.
+	EpicField string `json:"epic_field,omitempty"`
+	// Components, if given, are the only components the project's tickets
+	// may have, so a misspelt one fails rather than being created.
+	Components []string `json:"components,omitempty"`
+	// FieldDefaults fill the fields, by name or ID, which the project
+	// requires but a ticket doesn't set.
+	FieldDefaults map[string]interface{} `json:"field_defaults,omitempty"`
+}
+
+// projectConfigs are the settings from --project-config, by project key.
+var projectConfigs = map[string]projectConfig{}
+
+// projectConfigFor returns the settings for the project, whose key may be
+// in any case.
+func projectConfigFor(project string) projectConfig {
+	if config, ok := projectConfigs[project]; ok {
+		return config
+	}
+	for key, config := range projectConfigs {
+		if strings.EqualFold(key, project) {
+			return config
+		}
+	}
+	return projectConfig{}
+}
+
+// applyProjectConfig gives the ticket its project's epic_field, unless it
+// has its own, and checks its components against the project's.
+func applyProjectConfig(ticket *Ticket) error {
+	config := projectConfigFor(ticket.Project)
+	if ticket.CustomEpicField == "" {
+		ticket.CustomEpicField = config.EpicField
+	}
+	if len(config.Components) == 0 {
+		return nil
+	}
+	for _, component := range ticket.Components {
+		allowed := false
+		for _, name := range config.Components {
+			if strings.EqualFold(name, component) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf(
+				"component %q isn't one of %s's in --project-config: %s",
+				component,
+				ticket.Project,
+				strings.Join(config.Components, ", "),
+			)
+		}
+	}
+	return nil
+}
+
+// loadProjectConfig reads --project-config, if given.
+func loadProjectConfig() error {
+	if *projectConfigPath == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(*projectConfigPath)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &projectConfigs)
+}
+
+// requiredFieldDefaulter is a Tracker which can fill in the fields a project
+// requires, but an issue leaves out, from its field_defaults.
+type requiredFieldDefaulter interface {
+	// DefaultRequiredFields adds the defaults to the issue's fields, and
+	// returns the names of those it filled.
+	DefaultRequiredFields(issue *Issue, defaults map[string]interface{}) ([]string, error)
+}
+
+// defaultRequiredFields fills the required fields the issue leaves out from
+// its project's field_defaults, saying which it filled.
+func defaultRequiredFields(tracker Tracker, issue *Issue) error {
+	defaults := projectConfigFor(issue.Ticket.Project).FieldDefaults
+	if len(defaults) == 0 {
+		return nil
+	}
+	defaulter, ok := tracker.(requiredFieldDefaulter)
+	if !ok {
+		return fmt.Errorf("backend %s does not support field_defaults", *backend)
+	}
+	filled, err := defaulter.DefaultRequiredFields(issue, defaults)
+	if err != nil {
+		return err
+	}
+	for _, name := range filled {
+		fmt.Printf("Ticket %d: filling required field %s with %v from the project config\n", issue.Index, name, defaults[name])
+	}
+	return nil
+}
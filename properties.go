@@ -0,0 +1,104 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+)
+
+// runProperty is the issue property every created issue is tagged with.
+const runProperty = "epic-creator.run"
+
+// runPropertyValue records where an issue came from, so it can be found
+// later by run, ticket or content.
+type runPropertyValue struct {
+	Run    string `json:"run"`
+	Ticket int    `json:"ticket"`
+	Hash   string `json:"hash"`
+	// Version is the version of epic-creator which created the issue.
+	Version string `json:"version,omitempty"`
+}
+
+// propertySetter is implemented by trackers which can store arbitrary data
+// on issues.
+type propertySetter interface {
+	SetProperty(key string, name string, value interface{}) error
+}
+
+// contentHash is the SHA-256 of the issue's rendered summary and
+// description.
+func contentHash(issue Issue) string {
+	sum := sha256.Sum256([]byte(issue.Summary + "\x00" + issue.Description))
+	return hex.EncodeToString(sum[:])
+}
+
+// tagWithRun sets the run property on the issue, on trackers which have
+// issue properties.
+func tagWithRun(tracker Tracker, run *Run, issue Issue, created CreatedIssue) error {
+	p, ok := tracker.(propertySetter)
+	if !ok {
+		return nil
+	}
+	return p.SetProperty(created.Key, runProperty, runPropertyValue{
+		Run:     run.ID,
+		Ticket:  created.Ticket,
+		Hash:    contentHash(issue),
+		Version: run.Version,
+	})
+}
+
+func (t *jiraTracker) SetProperty(key string, name string, value interface{}) error {
+	req, err := t.client.NewRequest("PUT", "rest/api/2/issue/"+key+"/properties/"+name, value)
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Do(req, nil)
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+	return nil
+}
+
+// propertyStore is implemented by trackers which can also read and remove
+// the data stored on issues.
+type propertyStore interface {
+	propertySetter
+	// GetProperty decodes the property into v, reporting whether the
+	// issue has it.
+	GetProperty(key string, name string, v interface{}) (bool, error)
+	DeleteProperty(key string, name string) error
+}
+
+func (t *jiraTracker) GetProperty(key string, name string, v interface{}) (bool, error) {
+	req, err := t.client.NewRequest("GET", "rest/api/2/issue/"+key+"/properties/"+name, nil)
+	if err != nil {
+		return false, err
+	}
+
+	var property struct {
+		Value json.RawMessage `json:"value"`
+	}
+	resp, err := t.client.Do(req, &property)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, jiraAPIRequestErrorHandler(resp, err)
+	}
+	return true, json.Unmarshal(property.Value, v)
+}
+
+func (t *jiraTracker) DeleteProperty(key string, name string) error {
+	req, err := t.client.NewRequest("DELETE", "rest/api/2/issue/"+key+"/properties/"+name, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Do(req, nil)
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// rankChunkSize is the most issues the Agile API will rank in one request.
+const rankChunkSize = 50
+
+// ranker is implemented by trackers which can order issues in a backlog.
+type ranker interface {
+	// RankIssues orders the issues in the backlog, in the order given.
+	RankIssues(keys []string) error
+}
+
+type jiraRankRequest struct {
+	Issues          []string `json:"issues"`
+	RankAfterIssue  string   `json:"rankAfterIssue,omitempty"`
+	RankBeforeIssue string   `json:"rankBeforeIssue,omitempty"`
+}
+
+// RankIssues ranks each issue directly after the one before it, leaving the
+// first where it is.
+func (t *jiraTracker) RankIssues(keys []string) error {
+	for i := 1; i < len(keys); i += rankChunkSize {
+		end := i + rankChunkSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		body := jiraRankRequest{
+			Issues:         keys[i:end],
+			RankAfterIssue: keys[i-1],
+		}
+		req, err := t.client.NewRequest("PUT", "rest/agile/1.0/issue/rank", &body)
+		if err != nil {
+			return err
+		}
+		resp, err := t.client.Do(req, nil)
+		if err != nil {
+			return jiraAPIRequestErrorHandler(resp, err)
+		}
+	}
+	return nil
+}
+
+// createdInFileOrder returns the keys of the run's issues in the order of the
+// tickets they were created from. Epics created from --hierarchy are left
+// out.
+func createdInFileOrder(run *Run) []string {
+	created := make([]CreatedIssue, 0, len(run.Created))
+	for _, issue := range run.Created {
+		if issue.Ticket != hierarchyTicket {
+			created = append(created, issue)
+		}
+	}
+	sort.SliceStable(created, func(i, j int) bool {
+		return created[i].Ticket < created[j].Ticket
+	})
+
+	keys := make([]string, len(created))
+	for i, issue := range created {
+		keys[i] = issue.Key
+	}
+	return keys
+}
+
+// rankCreated ranks the run's issues in the backlog in tickets file order.
+func rankCreated(tracker Tracker, run *Run) error {
+	r, ok := tracker.(ranker)
+	if !ok {
+		return fmt.Errorf("backend %s does not support ranking", *backend)
+	}
+
+	keys := createdInFileOrder(run)
+	if err := r.RankIssues(keys); err != nil {
+		return err
+	}
+	fmt.Printf("Ranked %d issue(s) in tickets file order.\n", len(keys))
+	return nil
+}
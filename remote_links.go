@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+)
+
+// RemoteLink is a link from an issue to a page elsewhere, such as a design
+// doc or a dashboard.
+type RemoteLink struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// remoteLinker is implemented by trackers which can link issues to pages
+// elsewhere.
+type remoteLinker interface {
+	AddRemoteLink(key string, link RemoteLink) error
+}
+
+// addRemoteLinks links the issue to each of the ticket's remote links.
+func addRemoteLinks(tracker Tracker, run *Run, issue Issue, created CreatedIssue) error {
+	if len(issue.Ticket.RemoteLinks) == 0 {
+		return nil
+	}
+
+	l, ok := tracker.(remoteLinker)
+	if !ok {
+		return fmt.Errorf("backend %s does not support remote links", *backend)
+	}
+
+	for _, link := range issue.Ticket.RemoteLinks {
+		if link.Title == "" {
+			link.Title = link.URL
+		}
+		if err := l.AddRemoteLink(created.Key, link); err != nil {
+			return err
+		}
+		fmt.Printf("Linked %s to %s\n", created.Key, link.URL)
+	}
+	return nil
+}
+
+// AddRemoteLink adds a web link to the issue.
+func (t *jiraTracker) AddRemoteLink(key string, link RemoteLink) error {
+	body := map[string]interface{}{
+		"object": map[string]string{
+			"url":   link.URL,
+			"title": link.Title,
+		},
+	}
+	req, err := t.client.NewRequest("POST", "rest/api/2/issue/"+key+"/remotelink", body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Do(req, nil)
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+	return nil
+}
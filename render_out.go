@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	renderOut = createCmd.Flag(
+		"render-out",
+		"Directory to write each ticket's rendered summary and description to, one file per ticket. Combine with --dry-run to only render.",
+	).String()
+)
+
+var slugInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// renderedFileName names the file a rendered issue is written to, after its
+// index and summary so the files sort in tickets file order.
+func renderedFileName(issue Issue) string {
+	slug := strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(issue.Summary), "-"), "-")
+	if len(slug) > 50 {
+		slug = strings.TrimRight(slug[:50], "-")
+	}
+
+	ext := ".txt"
+	if *descriptionFormat == "markdown" {
+		ext = ".md"
+	}
+	return fmt.Sprintf("%03d-%s%s", issue.Index, slug, ext)
+}
+
+// writeRendered writes each issue's summary, a blank line and its
+// description to a file in dir.
+func writeRendered(dir string, issues []Issue) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, issue := range issues {
+		content := issue.Summary + "\n\n" + strings.TrimRight(issue.Description, "\n") + "\n"
+		if err := ioutil.WriteFile(filepath.Join(dir, renderedFileName(issue)), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Wrote %d rendered ticket(s) to %s\n", len(issues), dir)
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// defaultMarkdownReport lists the epic's issues under a heading per group.
+const defaultMarkdownReport = `# {{ .Epic.Key }}
+
+{{ .Status.Done }} of {{ .Status.Total }} issue(s) done, {{ .Status.PointsRemaining }} of {{ .Status.Points }} story points remaining.
+{{ range .Groups }}
+## {{ .Name }}
+{{ range .Issues }}
+- {{ .Key }}: {{ .Summary }}{{ if .Assignee }} ({{ .Assignee }}){{ end }}{{ end }}
+{{ end }}`
+
+// defaultHTMLReport is defaultMarkdownReport as an HTML fragment, for
+// pasting into an email.
+const defaultHTMLReport = `<h1><a href="{{ .Epic.URL }}">{{ .Epic.Key }}</a></h1>
+<p>{{ .Status.Done }} of {{ .Status.Total }} issue(s) done, {{ .Status.PointsRemaining }} of {{ .Status.Points }} story points remaining.</p>
+{{ range .Groups }}<h2>{{ .Name }}</h2>
+<ul>
+{{ range .Issues }}<li>{{ .Key }}: {{ .Summary }}{{ if .Assignee }} ({{ .Assignee }}){{ end }}</li>
+{{ end }}</ul>
+{{ end }}`
+
+var (
+	reportCmd = kingpin.Command(
+		"report",
+		"Render a report of an epic's issues, for release notes or a status email.",
+	)
+	reportFormat = reportCmd.Flag(
+		"format",
+		"Format of the report.",
+	).Default("markdown").Enum("markdown", "html")
+	reportGroupBy = reportCmd.Flag(
+		"group-by",
+		"What to group issues under in the report.",
+	).Default("status").Enum("status", "component")
+	reportTemplatePath = reportCmd.Flag(
+		"template",
+		"Path to template to render the report with, instead of the default.",
+	).ExistingFile()
+	reportEpic = reportCmd.Arg("epic", "Key of the epic.").Required().String()
+)
+
+// reportGroup is the issues with a status or component in common.
+type reportGroup struct {
+	Name   string
+	Issues []IssueStatus
+}
+
+// reportContext is the context the report template is executed with.
+type reportContext struct {
+	Epic   *Epic
+	Status epicStatus
+	Groups []reportGroup
+}
+
+// groupIssues groups the issues by status or component, in order of name.
+// Issues are in a group for each of their components, and those without one
+// are grouped under "No component".
+func groupIssues(issues []IssueStatus, by string) []reportGroup {
+	byName := make(map[string][]IssueStatus)
+	for _, issue := range issues {
+		names := []string{issue.Status}
+		if by == "component" {
+			names = issue.Components
+			if len(names) == 0 {
+				names = []string{"No component"}
+			}
+		}
+		for _, name := range names {
+			byName[name] = append(byName[name], issue)
+		}
+	}
+
+	groups := make([]reportGroup, 0, len(byName))
+	for name, grouped := range byName {
+		groups = append(groups, reportGroup{Name: name, Issues: grouped})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// reportTemplate is what text/template and html/template templates have in
+// common.
+type reportTemplate interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+func loadReportTemplate(format string, templatePath string) (reportTemplate, error) {
+	if format == "html" {
+		if templatePath == "" {
+			return htmltemplate.New("report").Funcs(htmltemplate.FuncMap(dateFuncs)).Parse(defaultHTMLReport)
+		}
+		return htmltemplate.New(filepath.Base(templatePath)).Funcs(htmltemplate.FuncMap(dateFuncs)).ParseFiles(templatePath)
+	}
+	if templatePath == "" {
+		return template.New("report").Funcs(dateFuncs).Parse(defaultMarkdownReport)
+	}
+	return template.New(filepath.Base(templatePath)).Funcs(dateFuncs).ParseFiles(templatePath)
+}
+
+func runEpicReport() {
+	tracker, err := newTracker(*backend)
+	if err != nil {
+		panic(err)
+	}
+	lister, ok := tracker.(epicLister)
+	if !ok {
+		panic(fmt.Errorf("backend %s does not support listing an epic's issues", *backend))
+	}
+	tmpl, err := loadReportTemplate(*reportFormat, *reportTemplatePath)
+	if err != nil {
+		panic(err)
+	}
+
+	epic, err := tracker.ResolveEpic(*reportEpic)
+	if err != nil {
+		panic(err)
+	}
+	issues, err := lister.EpicIssues(epic, "")
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := reportContext{
+		Epic:   epic,
+		Status: newEpicStatus(epic, issues),
+		Groups: groupIssues(issues, *reportGroupBy),
+	}
+	if err := tmpl.Execute(os.Stdout, ctx); err != nil {
+		panic(err)
+	}
+}
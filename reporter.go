@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Permissions needed to set the fields of a ticket beyond the usual ones.
+const (
+	permModifyReporter   = "MODIFY_REPORTER"
+	permSetIssueSecurity = "SET_ISSUE_SECURITY"
+)
+
+type jiraPermissions struct {
+	Permissions map[string]struct {
+		HavePermission bool `json:"havePermission"`
+	} `json:"permissions"`
+}
+
+// requiredPermissions are the permissions the caller needs in the ticket's
+// project, with what needs each.
+func requiredPermissions(ticket Ticket) map[string]string {
+	perms := make(map[string]string)
+	if ticket.Reporter != "" {
+		perms[permModifyReporter] = "setting reporter"
+	}
+	if ticket.SecurityLevel != "" {
+		perms[permSetIssueSecurity] = "setting security_level"
+	}
+	return perms
+}
+
+// missingPermissions returns a line for each of perms the caller lacks in
+// the project, so that a run stops before creating anything rather than
+// part way through.
+func missingPermissions(client *jiraClient, project string, perms map[string]string) ([]string, error) {
+	keys := make([]string, 0, len(perms))
+	for key := range perms {
+		keys = append(keys, key)
+	}
+
+	query := url.Values{}
+	query.Set("projectKey", project)
+	query.Set("permissions", strings.Join(keys, ","))
+	req, err := client.NewRequest("GET", "rest/api/2/mypermissions?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var have jiraPermissions
+	resp, err := client.Do(req, &have)
+	if err != nil {
+		return nil, jiraAPIRequestErrorHandler(resp, err)
+	}
+	sort.Strings(keys)
+	missing := make([]string, 0)
+	for _, key := range keys {
+		if !have.Permissions[key].HavePermission {
+			missing = append(missing, fmt.Sprintf("  %s: %s (%s), for %s", project, permissionNames[key], key, perms[key]))
+		}
+	}
+	return missing, nil
+}
+
+// securityLevel refers to a security level by ID if it's numeric, and by
+// name otherwise.
+func securityLevel(level string) map[string]string {
+	if strings.Trim(level, "0123456789") == "" {
+		return map[string]string{"id": level}
+	}
+	return map[string]string{"name": level}
+}
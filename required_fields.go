@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// createMetaField is a field of an issue type's create screen.
+type createMetaField struct {
+	FieldID         string `json:"fieldId"`
+	Key             string `json:"key"`
+	Name            string `json:"name"`
+	Required        bool   `json:"required"`
+	HasDefaultValue bool   `json:"hasDefaultValue"`
+	Schema          struct {
+		Type  string `json:"type"`
+		Items string `json:"items"`
+	} `json:"schema"`
+}
+
+// requiredFields returns the fields the project requires on issues of the
+// type which JIRA has no default for, fetching them on first use. Jira Cloud
+// and recent Data Center list them a type at a time; older Server only in
+// the whole project's create metadata.
+func (t *jiraTracker) requiredFields(project string, issueTypeID string) ([]createMetaField, error) {
+	key := project + " " + issueTypeID
+	t.mu.Lock()
+	fields, ok := t.createMeta[key]
+	t.mu.Unlock()
+	if ok {
+		return fields, nil
+	}
+
+	var all []createMetaField
+	err := cached(cacheKey("createmeta", key), &all, false, func() error {
+		var err error
+		if jiraCloud {
+			all, err = t.issueTypeCreateMeta(project, issueTypeID)
+		} else {
+			all, err = t.projectCreateMeta(project, issueTypeID)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range all {
+		if field.Required && !field.HasDefaultValue {
+			fields = append(fields, field)
+		}
+	}
+
+	t.mu.Lock()
+	if t.createMeta == nil {
+		t.createMeta = make(map[string][]createMetaField)
+	}
+	t.createMeta[key] = fields
+	t.mu.Unlock()
+	return fields, nil
+}
+
+func (t *jiraTracker) issueTypeCreateMeta(project string, issueTypeID string) ([]createMetaField, error) {
+	req, err := t.client.NewRequest(
+		"GET",
+		fmt.Sprintf("rest/api/2/issue/createmeta/%s/issuetypes/%s?maxResults=200", url.PathEscape(project), url.PathEscape(issueTypeID)),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	// Jira Cloud calls the list fields, and Data Center values.
+	var meta struct {
+		Fields []createMetaField `json:"fields"`
+		Values []createMetaField `json:"values"`
+	}
+	resp, err := t.client.Do(req, &meta)
+	if err != nil {
+		return nil, jiraAPIRequestErrorHandler(resp, err)
+	}
+	return append(meta.Fields, meta.Values...), nil
+}
+
+func (t *jiraTracker) projectCreateMeta(project string, issueTypeID string) ([]createMetaField, error) {
+	query := url.Values{}
+	query.Set("projectKeys", project)
+	query.Set("issuetypeIds", issueTypeID)
+	query.Set("expand", "projects.issuetypes.fields")
+	req, err := t.client.NewRequest("GET", "rest/api/2/issue/createmeta?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var meta struct {
+		Projects []struct {
+			IssueTypes []struct {
+				Fields map[string]createMetaField `json:"fields"`
+			} `json:"issuetypes"`
+		} `json:"projects"`
+	}
+	resp, err := t.client.Do(req, &meta)
+	if err != nil {
+		return nil, jiraAPIRequestErrorHandler(resp, err)
+	}
+	fields := make([]createMetaField, 0)
+	for _, p := range meta.Projects {
+		for _, issueType := range p.IssueTypes {
+			for id, field := range issueType.Fields {
+				field.FieldID = id
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// providedFields returns the IDs of the fields the issue will be created
+// with, from its ticket and its --fields-template fields.
+func (t *jiraTracker) providedFields(issue *Issue) (map[string]bool, error) {
+	ticket := issue.Ticket
+	provided := map[string]bool{
+		"summary":   true,
+		"project":   true,
+		"issuetype": true,
+		"parent":    jiraCloud,
+	}
+	for id, set := range map[string]bool{
+		"description":  issue.Description != "",
+		"assignee":     ticket.Assignee != "",
+		"reporter":     ticket.Reporter != "",
+		"security":     ticket.SecurityLevel != "",
+		"priority":     ticket.Priority != "",
+		"labels":       len(ticket.Labels) > 0,
+		"components":   len(ticket.Components) > 0,
+		"duedate":      ticket.DueDate != "",
+		"timetracking": timeTracking(ticket) != nil,
+	} {
+		provided[id] = set
+	}
+	if ticket.CustomEpicField != "" {
+		provided[ticket.CustomEpicField] = true
+	}
+	if ticket.StoryPoints != 0 {
+		field, err := t.storyPointsField()
+		if err != nil {
+			return nil, err
+		}
+		provided[field] = true
+	}
+	if ticket.StartDate != "" {
+		field, err := t.startDateField()
+		if err != nil {
+			return nil, err
+		}
+		provided[field] = true
+	}
+	if ticket.Team != "" {
+		field, err := t.fieldID("Team")
+		if err != nil {
+			return nil, err
+		}
+		provided[field] = true
+	}
+	for name := range issue.Fields {
+		field, err := t.resolveField(name)
+		if err != nil {
+			return nil, err
+		}
+		provided[field] = true
+	}
+	return provided, nil
+}
+
+// DefaultRequiredFields sets each field the issue's project and type require
+// which the issue doesn't, and which defaults has a value for by name or ID.
+// A plain string value is put in the form the field's type takes.
+func (t *jiraTracker) DefaultRequiredFields(issue *Issue, defaults map[string]interface{}) ([]string, error) {
+	project, err := t.project(issue.Ticket.Project, false)
+	if err != nil {
+		return nil, err
+	}
+	issueType, err := ticketIssueType(project)
+	if err != nil {
+		return nil, err
+	}
+	required, err := t.requiredFields(project.Key, issueType.ID)
+	if err != nil {
+		return nil, err
+	}
+	provided, err := t.providedFields(issue)
+	if err != nil {
+		return nil, err
+	}
+
+	filled := make([]string, 0)
+	for _, field := range required {
+		id := field.FieldID
+		if id == "" {
+			id = field.Key
+		}
+		if provided[id] || (!jiraCloud && strings.EqualFold(field.Name, "Epic Link")) {
+			continue
+		}
+		name := id
+		value, ok := defaults[id]
+		if !ok {
+			name = field.Name
+			value, ok = defaults[field.Name]
+		}
+		if !ok {
+			continue
+		}
+		if issue.Fields == nil {
+			issue.Fields = make(map[string]interface{})
+		}
+		issue.Fields[id] = fieldValue(field.Schema.Type, field.Schema.Items, value)
+		filled = append(filled, name)
+	}
+	return filled, nil
+}
+
+// fieldValue puts a string in the form JIRA takes for fields of the type:
+// the value of an option, or the name of a priority, version or the like.
+func fieldValue(kind string, items string, value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	if kind == "array" {
+		return []interface{}{fieldValue(items, "", s)}
+	}
+	switch kind {
+	case "option":
+		return map[string]string{"value": s}
+	case "user":
+		return jiraUser(s)
+	case "priority", "version", "component", "securitylevel", "resolution":
+		return map[string]string{"name": s}
+	}
+	return s
+}
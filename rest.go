@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// restClient is a minimal JSON-over-HTTP client, shared by the backends which
+// don't have a client library of their own.
+type restClient struct {
+	baseURL string
+	header  http.Header
+	client  *http.Client
+}
+
+func newRESTClient(baseURL string, header http.Header) *restClient {
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", "application/json")
+	}
+	return &restClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		header:  header,
+		client:  http.DefaultClient,
+	}
+}
+
+// do sends body, encoded as JSON, and decodes the response into v. Either may
+// be nil. A non-2xx response is returned as an error including the body.
+func (c *restClient) do(method string, path string, body interface{}, v interface{}) error {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, &reqBody)
+	if err != nil {
+		return err
+	}
+	for name, values := range c.header {
+		req.Header[name] = values
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, respBody)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// splitRef splits a reference such as "owner/repo#123" around the last sep
+// into the path and the number.
+func splitRef(ref string, sep string) (string, int, error) {
+	i := strings.LastIndex(ref, sep)
+	if i <= 0 {
+		return "", 0, fmt.Errorf("%q is not of the form path%snumber", ref, sep)
+	}
+
+	number, err := strconv.Atoi(ref[i+len(sep):])
+	if err != nil {
+		return "", 0, fmt.Errorf("%q is not of the form path%snumber", ref, sep)
+	}
+	return ref[:i], number, nil
+}
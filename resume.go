@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	resumeRunID = createCmd.Flag(
+		"resume",
+		"ID of a run which stopped partway through to carry on with: the issues it created are kept, and the rest of the tickets are created and recorded in the same run. The tickets file must be the one it was started with.",
+	).String()
+)
+
+// startRun starts the run, or with --resume loads the run to carry on with.
+func startRun(epic string, h *hierarchy) (*Run, error) {
+	if *resumeRunID == "" {
+		return newRun(*stateDir, *backend, epic), nil
+	}
+	if h != nil {
+		return nil, fmt.Errorf("--resume can't be used with --hierarchy")
+	}
+
+	run, err := loadRun(*stateDir, *resumeRunID)
+	if err != nil {
+		return nil, fmt.Errorf("--resume: %v", err)
+	}
+	if run.Backend != *backend {
+		return nil, fmt.Errorf("--resume: run %s was against %s, not %s", run.ID, run.Backend, *backend)
+	}
+	if !strings.EqualFold(run.Epic, epic) {
+		return nil, fmt.Errorf("--resume: run %s was for %s, not %s", run.ID, run.Epic, epic)
+	}
+	if !run.Finished.IsZero() && run.Error == "" {
+		return nil, fmt.Errorf("--resume: run %s already finished", run.ID)
+	}
+	run.Finished = time.Time{}
+	run.Error = ""
+	fmt.Printf("Resuming run %s, which created %d issue(s).\n", run.ID, len(run.Created))
+	return run, nil
+}
+
+// uncreated are the issues which the run hasn't created yet: all of them,
+// unless it's resumed.
+func uncreated(issues []Issue, run *Run) []Issue {
+	created := make(map[int]bool, len(run.Created))
+	for _, c := range run.Created {
+		created[c.Ticket] = true
+	}
+	remaining := make([]Issue, 0, len(issues))
+	for _, issue := range issues {
+		if !created[issue.Index] {
+			remaining = append(remaining, issue)
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	rollbackCmd = kingpin.Command(
+		"rollback",
+		"Delete (or close) every issue created by a previous run.",
+	)
+	rollbackCloseTransition = rollbackCmd.Flag(
+		"close",
+		"Instead of deleting issues, move them through the named transition (e.g. \"Done\").",
+	).String()
+	rollbackRunID = rollbackCmd.Arg("run-id", "ID of the run to roll back.").Required().String()
+)
+
+// rollbackRun undoes a run, most recently created issue first. If
+// closeTransition is empty the issues are deleted, otherwise they are moved
+// through that transition. Issues which were rolled back successfully are
+// dropped from the run, so a failed rollback can be retried.
+func rollbackRun(tracker Tracker, run *Run, closeTransition string) error {
+	for len(run.Created) > 0 {
+		issue := run.Created[len(run.Created)-1]
+
+		var err error
+		if closeTransition == "" {
+			err = tracker.DeleteIssue(issue.Key)
+		} else {
+			err = tracker.CloseIssue(issue.Key, closeTransition)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Rolled back: %s\n", issue.Key)
+
+		run.Created = run.Created[:len(run.Created)-1]
+		if err := run.Save(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditedRollback rolls back the run, recording the issues it rolled back
+// in the audit log.
+func auditedRollback(tracker Tracker, run *Run, closeTransition string) error {
+	keys := createdKeys(run.Created)
+	err := rollbackRun(tracker, run, closeTransition)
+
+	action := "rollback"
+	if closeTransition != "" {
+		action = "close"
+	}
+	entry := newAuditEntry(action, run, keys[len(run.Created):], err)
+	if auditErr := appendAudit(entry); auditErr != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write audit log: %v\n", auditErr)
+	}
+	return err
+}
+
+func runRollback() {
+	run, err := loadRun(*stateDir, *rollbackRunID)
+	if err != nil {
+		panic(err)
+	}
+
+	if run.Backend == "" {
+		run.Backend = "jira"
+	}
+	tracker, err := newTracker(run.Backend)
+	if err != nil {
+		panic(err)
+	}
+	if err := auditedRollback(tracker, run, *rollbackCloseTransition); err != nil {
+		fmt.Fprintf(os.Stderr, "Rollback of %s stopped with %d issue(s) remaining.\n", run.ID, len(run.Created))
+		panic(err)
+	}
+}
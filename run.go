@@ -0,0 +1,103 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+)
+
+// Run is the record of a single invocation of the create command. It is
+// written to the state directory as issues are created, so that a run which
+// dies partway through can still be inspected or rolled back.
+type Run struct {
+	ID      string         `json:"id"`
+	Backend string         `json:"backend"`
+	Epic    string         `json:"epic"`
+	Started time.Time      `json:"started"`
+	Created []CreatedIssue `json:"created"`
+	// Finished is when the run ended, and Error why it failed, if it did.
+	Finished time.Time `json:"finished,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	// Version is the version of epic-creator which made the run.
+	Version string `json:"version,omitempty"`
+
+	dir string
+}
+
+// CreatedIssue is an issue which was created during a Run.
+type CreatedIssue struct {
+	ID      string `json:"id"`
+	Key     string `json:"key"`
+	Project string `json:"project"`
+	Summary string `json:"summary"`
+	// Ticket is the index of the ticket the issue was created from, or -1
+	// for epics created from --hierarchy.
+	Ticket int `json:"ticket"`
+}
+
+func newRunID() string {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(buf)
+}
+
+func newRun(dir string, backend string, epic string) *Run {
+	return &Run{
+		ID:      newRunID(),
+		Backend: backend,
+		Epic:    epic,
+		Started: time.Now().UTC(),
+		Created: make([]CreatedIssue, 0),
+		Version: toolVersion(),
+		dir:     dir,
+	}
+}
+
+func runFilePath(dir string, id string) string {
+	return path.Join(dir, id+".json")
+}
+
+func loadRun(dir string, id string) (*Run, error) {
+	data, err := ioutil.ReadFile(runFilePath(dir, id))
+	if err != nil {
+		return nil, err
+	}
+
+	run := &Run{dir: dir}
+	err = json.Unmarshal(data, run)
+	return run, err
+}
+
+// Record adds an issue to the run and persists the run to disk.
+func (run *Run) Record(issue CreatedIssue) error {
+	run.Created = append(run.Created, issue)
+	return run.Save()
+}
+
+// Finish records the end of the run, and its error if it failed.
+func (run *Run) Finish(err error) error {
+	run.Finished = time.Now().UTC()
+	if err != nil {
+		run.Error = err.Error()
+	}
+	return run.Save()
+}
+
+// Save writes the run to its state directory.
+func (run *Run) Save() error {
+	if err := os.MkdirAll(run.dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(run, "", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(runFilePath(run.dir, run.ID), data, 0644)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+)
+
+var (
+	sanitize = createCmd.Flag(
+		"sanitize",
+		"Replace smart quotes, dashes and other typographic characters in rendered summaries, descriptions and comments with plain ASCII, and strip control characters.",
+	).Bool()
+	transliterateEmoji = createCmd.Flag(
+		"transliterate-emoji",
+		"With --sanitize, replace emoji with their :shortcode:, dropping those without one.",
+	).Bool()
+)
+
+// typographic maps characters some instances reject or mangle onto plain
+// ASCII.
+var typographic = strings.NewReplacer(
+	"‘", "'", "’", "'", "‚", "'", "‛", "'",
+	"“", `"`, "”", `"`, "„", `"`, "‟", `"`,
+	"′", "'", "″", `"`,
+	"–", "-", "—", "--", "−", "-",
+	"…", "...",
+	"\u00a0", " ", "\u202f", " ", "\u2009", " ",
+)
+
+// emojiShortcodes are the shortcodes of common emoji.
+var emojiShortcodes = map[rune]string{
+	'\u2705':     ":white_check_mark:",
+	'\u274c':     ":x:",
+	'\u26a0':     ":warning:",
+	'\u2728':     ":sparkles:",
+	'\u2b50':     ":star:",
+	'\u2764':     ":heart:",
+	'\U0001f41b': ":bug:",
+	'\U0001f440': ":eyes:",
+	'\U0001f44d': ":+1:",
+	'\U0001f44e': ":-1:",
+	'\U0001f4a5': ":boom:",
+	'\U0001f4a1': ":bulb:",
+	'\U0001f4dd': ":memo:",
+	'\U0001f525': ":fire:",
+	'\U0001f527': ":wrench:",
+	'\U0001f512': ":lock:",
+	'\U0001f389': ":tada:",
+	'\U0001f680': ":rocket:",
+	'\U0001f6a7': ":construction:",
+	'\U0001f6a8': ":rotating_light:",
+}
+
+// isEmoji reports whether r is in one of the emoji blocks.
+func isEmoji(r rune) bool {
+	return (r >= 0x1f300 && r <= 0x1faff) ||
+		(r >= 0x2600 && r <= 0x27bf) ||
+		(r >= 0x2b00 && r <= 0x2bff) ||
+		(r >= 0x1f1e6 && r <= 0x1f1ff)
+}
+
+// sanitizeText normalizes typographic characters and strips control
+// characters other than newlines and tabs.
+func sanitizeText(s string) string {
+	s = typographic.Replace(s)
+
+	b := bytes.NewBufferString("")
+	for _, r := range s {
+		switch {
+		case r == '\n' || r == '\t':
+			b.WriteRune(r)
+		case unicode.IsControl(r):
+		case *transliterateEmoji && (r == '\u200d' || r == '\ufe0f'):
+			// Joiners and variation selectors only modify the emoji
+			// around them.
+		case *transliterateEmoji && isEmoji(r):
+			b.WriteString(emojiShortcodes[r])
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// sanitizeIssue sanitizes the rendered issue, if --sanitize is given.
+func sanitizeIssue(issue *Issue) {
+	if !*sanitize {
+		return
+	}
+
+	issue.Summary = sanitizeText(issue.Summary)
+	issue.Description = sanitizeText(issue.Description)
+	issue.Comment = sanitizeText(issue.Comment)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	saveFilter = createCmd.Flag(
+		"save-filter",
+		"Save the JQL finding the run's issues as a JIRA filter with this name, to build a board or dashboard on.",
+	).String()
+)
+
+// filterSaver is implemented by trackers which can save searches.
+type filterSaver interface {
+	// SaveFilter saves the search, returning where to view it.
+	SaveFilter(name string, jql string) (string, error)
+}
+
+// createdJQL is JQL which finds the run's issues: by their label with
+// --run-label, which keeps finding them as they're moved about, or else by
+// their keys.
+func createdJQL(run *Run) string {
+	if *runLabel {
+		return fmt.Sprintf("labels = %q", runLabelName(run))
+	}
+	keys := make([]string, len(run.Created))
+	for i, created := range run.Created {
+		keys[i] = created.Key
+	}
+	return fmt.Sprintf("key in (%s)", strings.Join(keys, ", "))
+}
+
+// printCreatedQuery prints how to find the run's issues, saving the search
+// with --save-filter.
+func printCreatedQuery(tracker Tracker, run *Run) error {
+	if *backend != "jira" {
+		if *runLabel {
+			fmt.Printf("This run's issues are labelled %s.\n", runLabelName(run))
+		}
+		if *saveFilter != "" {
+			return fmt.Errorf("backend %s does not support saved filters", *backend)
+		}
+		return nil
+	}
+
+	jql := createdJQL(run)
+	fmt.Printf("JQL for this run's issues: %s\n", jql)
+	if *saveFilter == "" {
+		return nil
+	}
+	s, ok := tracker.(filterSaver)
+	if !ok {
+		return fmt.Errorf("backend %s does not support saved filters", *backend)
+	}
+	url, err := s.SaveFilter(*saveFilter, jql)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Saved filter %q: %s\n", *saveFilter, url)
+	return nil
+}
+
+// SaveFilter saves the search as a filter owned by the user.
+func (t *jiraTracker) SaveFilter(name string, jql string) (string, error) {
+	body := map[string]string{
+		"name":        name,
+		"jql":         jql,
+		"description": "Issues created by epic-creator.",
+	}
+	req, err := t.client.NewRequest("POST", "rest/api/2/filter", body)
+	if err != nil {
+		return "", err
+	}
+
+	var filter struct {
+		ViewURL string `json:"viewUrl"`
+	}
+	resp, err := t.client.Do(req, &filter)
+	if err != nil {
+		return "", jiraAPIRequestErrorHandler(resp, err)
+	}
+	return filter.ViewURL, nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	schedule = createCmd.Flag(
+		"schedule",
+		"Keep running, and create a fresh --hierarchy and its tickets whenever the cron expression (minute hour day-of-month month day-of-week) comes round, e.g. \"0 9 1 * *\" for 9am on the first of every month.",
+	).String()
+)
+
+// cronField is the values a field of a cron expression matches.
+type cronField map[int]bool
+
+// cronSchedule is a parsed cron expression.
+type cronSchedule struct {
+	minute, hour, dom, month, dow cronField
+	// domAny and dowAny are set when the day of the month or week is *,
+	// as a day matches either of them if both are restricted.
+	domAny, dowAny bool
+}
+
+// parseCronField parses a comma-separated list of *, values, ranges and
+// steps, such as "*/15" or "1-5,7", of values from min to max.
+func parseCronField(field string, min int, max int) (cronField, error) {
+	values := make(cronField)
+	for _, item := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("bad step in %q", item)
+			}
+			step = n
+			item = item[:i]
+		}
+
+		lo, hi := min, max
+		if item != "*" {
+			bounds := strings.SplitN(item, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return nil, fmt.Errorf("bad value %q", item)
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return nil, fmt.Errorf("bad value %q", item)
+				}
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return nil, fmt.Errorf("%q is out of the range %d-%d", item, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+func parseCron(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q should have 5 fields: minute hour day-of-month month day-of-week", expr)
+	}
+
+	bounds := [][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	parsed := make([]cronField, len(fields))
+	for i, field := range fields {
+		values, err := parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("cron expression %q: %v", expr, err)
+		}
+		parsed[i] = values
+	}
+	// Sunday is both 0 and 7.
+	if parsed[4][7] {
+		parsed[4][0] = true
+	}
+
+	return &cronSchedule{
+		minute: parsed[0],
+		hour:   parsed[1],
+		dom:    parsed[2],
+		month:  parsed[3],
+		dow:    parsed[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}, nil
+}
+
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	dom := s.dom[t.Day()]
+	dow := s.dow[int(t.Weekday())]
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	}
+	return dom || dow
+}
+
+// Next returns the first time after t which the schedule matches, or the
+// zero time if there isn't one within five years.
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	for end := t.AddDate(5, 0, 0); t.Before(end); t = t.Add(time.Minute) {
+		if s.month[int(t.Month())] && s.matchesDay(t) && s.hour[t.Hour()] && s.minute[t.Minute()] {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// scheduledAt is when the current scheduled run was due, or the zero time
+// outside of --schedule.
+var scheduledAt time.Time
+
+// runSchedule creates the hierarchy and its tickets each time the schedule
+// comes round. A failed run is reported, and the next one goes ahead.
+func runSchedule() {
+	if *hierarchyPath == "" {
+		panic(fmt.Errorf("--schedule needs a --hierarchy of epics to create each time"))
+	}
+	s, err := parseCron(*schedule)
+	if err != nil {
+		panic(err)
+	}
+
+	for {
+		next := s.Next(time.Now())
+		if next.IsZero() {
+			panic(fmt.Errorf("%q never comes round", *schedule))
+		}
+		fmt.Printf("Next run at %s.\n", next.Format(time.RFC1123))
+		time.Sleep(time.Until(next))
+
+		scheduledAt = next
+		runCreateRecovering()
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	schemaCmd = kingpin.Command(
+		"schema",
+		"Print the JSON Schema of the tickets file, for editor integration.",
+	)
+)
+
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindStrings
+	kindNumber
+	kindObject
+	kindMatrix
+	kindDate
+	kindLinks
+)
+
+// ticketField describes a field of a ticket, for both the schema and the
+// validation of tickets files against it.
+type ticketField struct {
+	kind        fieldKind
+	description string
+}
+
+// ticketFields are the fields a ticket may have. Keep this in step with
+// Ticket.
+var ticketFields = map[string]ticketField{
+	"project":            {kindString, "Project to create the issue in."},
+	"params":             {kindObject, "Data available to the templates as .Params."},
+	"params_file":        {kindString, "File of params, relative to the tickets file, which the ticket's own params override."},
+	"custom_epic_field":  {kindString, "ID of the custom field holding the epic, instead of the epic link."},
+	"attachments":        {kindStrings, "Files to upload to the issue, relative to the tickets file."},
+	"watchers":           {kindStrings, "Users to add as watchers of the issue."},
+	"remote_links":       {kindLinks, "Pages, each with a title and url, to link the issue to."},
+	"comment":            {kindString, "Template for a comment to post on the issue once it's created."},
+	"transition":         {kindString, "Workflow transition to move the issue through once it's created."},
+	"start_date":         {kindDate, "When work on the issue starts, as YYYY-MM-DD."},
+	"due_date":           {kindDate, "When the issue is due, as YYYY-MM-DD."},
+	"estimate":           {kindString, "Original time estimate, such as 2d 4h."},
+	"remaining_estimate": {kindString, "Remaining time estimate, if not the original estimate."},
+	"worklog":            {kindObject, "Work to log once the issue is created, with time_spent and optionally started and comment."},
+	"story_points":       {kindNumber, "Story point estimate."},
+	"assignee":           {kindString, "User to assign the issue to."},
+	"reporter":           {kindString, "User to file the issue on behalf of."},
+	"security_level":     {kindString, "Name or ID of the issue's security level."},
+	"team":               {kindString, "Name or ID of the Advanced Roadmaps team the issue belongs to."},
+	"priority":           {kindString, "Name of the issue's priority."},
+	"labels":             {kindStrings, "Labels to add to the issue."},
+	"components":         {kindStrings, "Project components the issue belongs to."},
+	"matrix":             {kindMatrix, "Lists of values; the ticket is expanded into one ticket per combination."},
+	"matrix_from":        {kindObject, "Params, by matrix key, holding lists to add to the matrix."},
+	"id":                 {kindString, "Name of the ticket for the depends_on of other tickets."},
+	"depends_on":         {kindStrings, "Ids of tickets which block this one."},
+	"globals":            {kindObject, "Run-wide values for every template, as .Globals, on an entry which isn't a ticket."},
+	"include":            {kindString, "Path or glob of tickets files whose tickets take the place of this entry."},
+	"tags":               {kindStrings, "Tags for selecting the ticket with --tag and --skip-tag."},
+	"when":               {kindString, "Template which skips the ticket if it renders false, 0, no or nothing."},
+	"epic":               {kindString, "Epic to create the issue in, instead of the one on the command line."},
+}
+
+var dateValue = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+func (f ticketField) schema() map[string]interface{} {
+	s := map[string]interface{}{"description": f.description}
+	switch f.kind {
+	case kindString:
+		s["type"] = "string"
+	case kindDate:
+		s["type"] = "string"
+		s["pattern"] = dateValue.String()
+	case kindStrings:
+		s["type"] = "array"
+		s["items"] = map[string]string{"type": "string"}
+	case kindNumber:
+		s["type"] = "number"
+	case kindObject:
+		s["type"] = "object"
+	case kindMatrix:
+		s["type"] = "object"
+		s["additionalProperties"] = map[string]string{"type": "array"}
+	case kindLinks:
+		s["type"] = "array"
+		s["items"] = map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"title": map[string]string{"type": "string"},
+				"url":   map[string]string{"type": "string"},
+			},
+			"required":             []string{"url"},
+			"additionalProperties": false,
+		}
+	}
+	return s
+}
+
+// ticketsSchema returns the JSON Schema of the tickets file.
+func ticketsSchema() map[string]interface{} {
+	properties := make(map[string]interface{}, len(ticketFields))
+	for name, field := range ticketFields {
+		properties[name] = field.schema()
+	}
+	return map[string]interface{}{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"title":       "epic-creator tickets file",
+		"description": "Tickets to create issues from. Newline-delimited JSON, with one ticket per line, is also accepted.",
+		"type":        "array",
+		"items": map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		},
+	}
+}
+
+// check reports what's wrong with value as this field, if anything.
+func (f ticketField) check(value interface{}) error {
+	switch f.kind {
+	case kindString:
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("must be a string")
+		}
+	case kindDate:
+		s, ok := value.(string)
+		if !ok || !dateValue.MatchString(s) {
+			return fmt.Errorf("must be a date, as YYYY-MM-DD")
+		}
+	case kindStrings:
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("must be a list of strings")
+		}
+		for i, item := range items {
+			if _, ok := item.(string); !ok {
+				return fmt.Errorf("item %d must be a string", i)
+			}
+		}
+	case kindNumber:
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("must be a number")
+		}
+	case kindObject:
+		if _, ok := value.(map[string]interface{}); !ok {
+			return fmt.Errorf("must be an object")
+		}
+	case kindMatrix:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("must be an object of lists")
+		}
+		for key, values := range m {
+			if _, ok := values.([]interface{}); !ok {
+				return fmt.Errorf("%q must be a list", key)
+			}
+		}
+	case kindLinks:
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("must be a list of links")
+		}
+		for i, item := range items {
+			link, ok := item.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("item %d must be an object with a title and url", i)
+			}
+			for name, v := range link {
+				if name != "title" && name != "url" {
+					return fmt.Errorf("item %d: unknown field %q", i, name)
+				}
+				if _, ok := v.(string); !ok {
+					return fmt.Errorf("item %d: %s must be a string", i, name)
+				}
+			}
+			if link["url"] == nil {
+				return fmt.Errorf("item %d has no url", i)
+			}
+		}
+	}
+	return nil
+}
+
+// validateTicket checks a ticket against the schema. Field names are
+// matched ignoring case, as encoding/json does.
+func validateTicket(raw json.RawMessage) error {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return fmt.Errorf("must be an object")
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := fields[name]
+		field, ok := ticketFields[strings.ToLower(name)]
+		if !ok {
+			return fmt.Errorf("field %q: unknown field", name)
+		}
+		if value == nil {
+			continue
+		}
+		if err := field.check(value); err != nil {
+			return fmt.Errorf("field %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func runSchema() {
+	data, err := json.MarshalIndent(ticketsSchema(), "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	os.Stdout.Write(append(data, '\n'))
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	vaultSecret = kingpin.Flag(
+		"vault-secret",
+		"Path of a HashiCorp Vault secret to read credentials from instead of the auth file, e.g. secret/data/epic-creator. Vault is at $VAULT_ADDR, and logged into with $VAULT_TOKEN or ~/.vault-token.",
+	).String()
+	vaultAddr = kingpin.Flag(
+		"vault-addr",
+		"HashiCorp Vault URL, for --vault-secret.",
+	).Envar("VAULT_ADDR").String()
+	awsSecret = kingpin.Flag(
+		"aws-secret",
+		"Name or ARN of an AWS Secrets Manager secret, holding a JSON object, to read credentials from instead of the auth file.",
+	).String()
+	awsCommand = kingpin.Flag(
+		"aws",
+		"Command with which to read --aws-secret. It finds AWS credentials and the region the usual ways.",
+	).Default("aws").String()
+	secretUserField = kingpin.Flag(
+		"secret-user-field",
+		"Field of the --vault-secret or --aws-secret holding the user.",
+	).Default("user").String()
+	secretPasswordField = kingpin.Flag(
+		"secret-password-field",
+		"Field of the --vault-secret or --aws-secret holding the password or token.",
+	).Default("password").String()
+)
+
+// secretCreds are the credentials read from a secret store, which is only
+// asked once per run.
+var secretCreds *Creds
+
+// credsFromSecret returns the credentials from --vault-secret or
+// --aws-secret, or nil if neither is given.
+func credsFromSecret() (*Creds, error) {
+	if secretCreds != nil {
+		return secretCreds, nil
+	}
+
+	var secret map[string]interface{}
+	var err error
+	switch {
+	case *vaultSecret != "":
+		secret, err = readVaultSecret(*vaultSecret)
+	case *awsSecret != "":
+		secret, err = readAWSSecret(*awsSecret)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	creds := &Creds{}
+	for field, value := range map[string]*string{
+		*secretUserField:     &creds.User,
+		*secretPasswordField: &creds.Password,
+	} {
+		s, ok := secret[field].(string)
+		if !ok {
+			return nil, fmt.Errorf("secret has no string field %q", field)
+		}
+		*value = s
+	}
+	secretCreds = creds
+	return creds, nil
+}
+
+// vaultToken returns the token to log into Vault with, from $VAULT_TOKEN or
+// the file the vault command keeps it in.
+func vaultToken() (string, error) {
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	u, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	data, err := ioutil.ReadFile(filepath.Join(u.HomeDir, ".vault-token"))
+	if err != nil {
+		return "", fmt.Errorf("VAULT_TOKEN isn't set and %v", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// readVaultSecret reads a secret from Vault, from either version of the KV
+// secrets engine.
+func readVaultSecret(secretPath string) (map[string]interface{}, error) {
+	if *vaultAddr == "" {
+		return nil, fmt.Errorf("--vault-addr or VAULT_ADDR is required for --vault-secret")
+	}
+	token, err := vaultToken()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", strings.TrimRight(*vaultAddr, "/")+"/v1/"+strings.TrimLeft(secretPath, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("reading %s from Vault: %s: %s", secretPath, resp.Status, bytes.TrimSpace(body))
+	}
+
+	var result struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	// KV version 2 nests the secret, alongside its metadata.
+	if inner, ok := result.Data["data"].(map[string]interface{}); ok {
+		if _, ok := result.Data["metadata"]; ok {
+			return inner, nil
+		}
+	}
+	return result.Data, nil
+}
+
+// readAWSSecret reads a JSON secret from AWS Secrets Manager with the aws
+// command, which takes care of finding credentials.
+func readAWSSecret(secretID string) (map[string]interface{}, error) {
+	var out bytes.Buffer
+	cmd := exec.Command(*awsCommand, "secretsmanager", "get-secret-value", "--secret-id", secretID, "--query", "SecretString", "--output", "text")
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("reading %s from AWS Secrets Manager: %v", secretID, err)
+	}
+
+	var secret map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &secret); err != nil {
+		return nil, fmt.Errorf("%s isn't a JSON object: %v", secretID, err)
+	}
+	return secret, nil
+}
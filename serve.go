@@ -0,0 +1,582 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	serveCmd = kingpin.Command(
+		"serve",
+		"Serve an HTTP (and optionally gRPC) API which creates issues from tickets submitted to it, as jobs run in the background.",
+	)
+	serveListen = serveCmd.Flag(
+		"listen",
+		"Address to listen on.",
+	).Default("localhost:8081").String()
+	serveToken = serveCmd.Flag(
+		"token",
+		"Bearer token clients must present. Anyone who can reach the server may submit jobs without one.",
+	).Envar("EPIC_CREATOR_SERVE_TOKEN").String()
+	serveJobsDir = serveCmd.Flag(
+		"jobs-dir",
+		"Directory in which jobs are kept, so they survive the server restarting. Defaults to jobs in the --state-dir.",
+	).String()
+	serveCreateFlags = serveCmd.Flag(
+		"create-flag",
+		"Flag to run create with for every job, e.g. --create-flag=--epic-comment. May be repeated.",
+	).Strings()
+)
+
+// serveFlags are the serve command's own flags, which aren't passed on to
+// the jobs.
+var serveFlags = []string{"--listen", "--grpc-listen", "--token", "--jobs-dir", "--create-flag"}
+
+// Job states.
+const (
+	jobQueued    = "queued"
+	jobRunning   = "running"
+	jobSucceeded = "succeeded"
+	jobFailed    = "failed"
+	jobCancelled = "cancelled"
+	// jobInterrupted is a job which was running when the server stopped,
+	// and couldn't be queued to resume.
+	jobInterrupted = "interrupted"
+)
+
+// jobQueueSize is how many jobs may wait to be run.
+const jobQueueSize = 100
+
+// jobOutputLimit is how much of the end of a job's output is kept.
+const jobOutputLimit = 64 * 1024
+
+// jobStopTimeout is how long a cancelled job has to stop by itself, between
+// issues, before its process is killed.
+const jobStopTimeout = time.Minute
+
+// jobRequest is the body with which a job is submitted.
+type jobRequest struct {
+	Epic    string   `json:"epic"`
+	Tickets []Ticket `json:"tickets"`
+	DryRun  bool     `json:"dry_run,omitempty"`
+}
+
+// Job is a run of create submitted to the server.
+type Job struct {
+	ID        string         `json:"id"`
+	Status    string         `json:"status"`
+	Epic      string         `json:"epic"`
+	DryRun    bool           `json:"dry_run,omitempty"`
+	Submitted time.Time      `json:"submitted"`
+	Finished  time.Time      `json:"finished,omitempty"`
+	Run       string         `json:"run,omitempty"`
+	Created   []CreatedIssue `json:"created"`
+	Error     string         `json:"error,omitempty"`
+	// Output is the end of the job's output, up to jobOutputLimit bytes.
+	Output string `json:"output,omitempty"`
+
+	tickets   []Ticket
+	cmd       *exec.Cmd
+	cancelled bool
+}
+
+// snapshot copies the job, so it can be written out after s.mu is
+// released. s.mu must be held.
+func (job *Job) snapshot() Job {
+	c := *job
+	c.Created = append([]CreatedIssue{}, job.Created...)
+	return c
+}
+
+// savedJob is a job as it's kept in the jobs directory, with its tickets.
+type savedJob struct {
+	*Job
+	Tickets []Ticket `json:"tickets"`
+}
+
+// jobServer runs jobs one at a time, in the order they're submitted. Each
+// is run as a separate create process, as create's options are global.
+type jobServer struct {
+	mu    sync.Mutex
+	jobs  map[string]*Job
+	queue chan *Job
+	// args are the global flags the server was started with, to run the
+	// jobs with.
+	args []string
+	dir  string
+}
+
+func newJobServer(args []string, dir string) *jobServer {
+	return &jobServer{
+		jobs:  make(map[string]*Job),
+		queue: make(chan *Job, jobQueueSize),
+		args:  args,
+		dir:   dir,
+	}
+}
+
+// save writes the job to the jobs directory. s.mu must be held.
+func (s *jobServer) save(job *Job) {
+	data, err := json.MarshalIndent(savedJob{job, job.tickets}, "", "    ")
+	if err == nil {
+		err = ioutil.WriteFile(path.Join(s.dir, job.ID+".json"), data, 0644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save job %s: %v\n", job.ID, err)
+	}
+}
+
+// load reads the jobs left by the server's last run, queueing those which
+// hadn't started or were running. A job which was running is resumed, from
+// its run if it had got as far as starting one, so the issues it created
+// aren't created again.
+func (s *jobServer) load() error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	files, err := filepath.Glob(path.Join(s.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		saved := savedJob{Job: &Job{}}
+		if err := json.Unmarshal(data, &saved); err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		job := saved.Job
+		job.tickets = saved.Tickets
+		s.jobs[job.ID] = job
+
+		switch job.Status {
+		case jobQueued:
+			if len(s.queue) == jobQueueSize {
+				return fmt.Errorf("more than %d jobs are queued in %s", jobQueueSize, s.dir)
+			}
+			s.queue <- job
+		case jobRunning:
+			if len(s.queue) == jobQueueSize {
+				job.Status = jobInterrupted
+				job.Error = "the server stopped while the job was running, and too many jobs are queued to resume it"
+				if job.Run != "" {
+					job.Error += "; resume run " + job.Run + " with create --resume, or roll it back"
+				}
+				s.save(job)
+				continue
+			}
+			job.Status = jobQueued
+			job.Output += "\nResuming after the server restarted.\n"
+			s.save(job)
+			s.queue <- job
+		}
+	}
+	return nil
+}
+
+// jobArgs strips the serve command and its flags from args, leaving the
+// global flags.
+func jobArgs(args []string) []string {
+	kept := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == serveCmd.FullCommand() {
+			continue
+		}
+		own := false
+		for _, flag := range serveFlags {
+			if arg == flag {
+				// The value is the next argument.
+				i++
+				own = true
+			}
+			if strings.HasPrefix(arg, flag+"=") {
+				own = true
+			}
+		}
+		if !own {
+			kept = append(kept, arg)
+		}
+	}
+	return kept
+}
+
+var runIDLine = regexp.MustCompile(`(?m)^Run ID: (\S+)$`)
+
+// jobOutput collects the output of a job's process, picking its run ID out
+// of it, so the job's progress can be followed while it runs.
+type jobOutput struct {
+	s   *jobServer
+	job *Job
+}
+
+func (o jobOutput) Write(p []byte) (int, error) {
+	o.s.mu.Lock()
+	defer o.s.mu.Unlock()
+
+	o.job.Output += string(p)
+	if o.job.Run == "" {
+		if m := runIDLine.FindStringSubmatch(o.job.Output); m != nil {
+			o.job.Run = m[1]
+		}
+	}
+	if n := len(o.job.Output); n > jobOutputLimit {
+		o.job.Output = o.job.Output[n-jobOutputLimit:]
+		// Start at a line, rather than partway through one.
+		if i := strings.IndexByte(o.job.Output, '\n'); i >= 0 {
+			o.job.Output = o.job.Output[i+1:]
+		}
+	}
+	return len(p), nil
+}
+
+// runJob runs create for the job, with the tickets in a temporary file.
+func (s *jobServer) runJob(job *Job) error {
+	data, err := json.Marshal(job.tickets)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile("", "epic-creator-job-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args := append([]string{}, s.args...)
+	args = append(args, "create", "--tickets-json", f.Name())
+	args = append(args, *serveCreateFlags...)
+	if job.DryRun {
+		args = append(args, "--dry-run")
+	} else if job.Run != "" {
+		// The job was running when the server stopped.
+		args = append(args, "--resume", job.Run)
+	}
+	args = append(args, "--", job.Epic)
+
+	cmd := exec.Command(self, args...)
+	cmd.Stdout = jobOutput{s, job}
+	cmd.Stderr = jobOutput{s, job}
+	s.mu.Lock()
+	if job.cancelled {
+		s.mu.Unlock()
+		return nil
+	}
+	err = cmd.Start()
+	job.cmd = cmd
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("create failed: %v", err)
+	}
+	return nil
+}
+
+// work runs queued jobs until the queue is closed.
+func (s *jobServer) work() {
+	for job := range s.queue {
+		s.mu.Lock()
+		if job.cancelled {
+			s.mu.Unlock()
+			continue
+		}
+		job.Status = jobRunning
+		s.save(job)
+		s.mu.Unlock()
+
+		err := s.runJob(job)
+
+		s.mu.Lock()
+		job.Finished = time.Now().UTC()
+		job.cmd = nil
+		switch {
+		case job.cancelled:
+			job.Status = jobCancelled
+		case err != nil:
+			job.Status = jobFailed
+			job.Error = err.Error()
+		default:
+			job.Status = jobSucceeded
+		}
+		s.refresh(job)
+		s.save(job)
+		s.mu.Unlock()
+		fmt.Printf("Job %s %s\n", job.ID, job.Status)
+	}
+}
+
+// refresh updates the job's created issues from its run, which is saved as
+// each issue is created. s.mu must be held.
+func (s *jobServer) refresh(job *Job) {
+	if job.Run == "" {
+		return
+	}
+	if run, err := loadRun(*stateDir, job.Run); err == nil {
+		job.Created = run.Created
+	}
+}
+
+func (s *jobServer) authorized(r *http.Request) bool {
+	return *serveToken == "" || r.Header.Get("Authorization") == "Bearer "+*serveToken
+}
+
+func (s *jobServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeServeError(w, http.StatusUnauthorized, "missing or wrong bearer token")
+		return
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+	switch {
+	case r.Method == "POST" && path == "jobs":
+		s.submit(w, r)
+	case r.Method == "GET" && path == "jobs":
+		writeJSON(w, http.StatusOK, s.listJobs())
+	case r.Method == "GET" && strings.HasPrefix(path, "jobs/"):
+		s.get(w, strings.TrimPrefix(path, "jobs/"))
+	case r.Method == "DELETE" && strings.HasPrefix(path, "jobs/"):
+		s.cancel(w, strings.TrimPrefix(path, "jobs/"))
+	default:
+		writeServeError(w, http.StatusNotFound, "%s %s is not part of the API", r.Method, r.URL.Path)
+	}
+}
+
+// jobError is a request to the server which can't be carried out, with the
+// HTTP status it's answered with.
+type jobError struct {
+	status  int
+	message string
+}
+
+func (e *jobError) Error() string {
+	return e.message
+}
+
+func newJobError(status int, format string, args ...interface{}) *jobError {
+	return &jobError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+// submitJob queues a job of the request, returning it as queued. It's shared
+// by the HTTP and gRPC APIs, as are listJobs, getJob and cancelJob.
+func (s *jobServer) submitJob(req jobRequest) (Job, error) {
+	if req.Epic == "" || len(req.Tickets) == 0 {
+		return Job{}, newJobError(http.StatusBadRequest, "a job needs an epic and tickets")
+	}
+	for i, ticket := range req.Tickets {
+		// These would read files on the server, or run the jsonnet and
+		// cue commands on them. A params_file URL would be fetched from
+		// the server, with the --params-header credentials. Without a
+		// params_file, matrix_from can only name the job's own params.
+		if len(ticket.Attachments) > 0 || ticket.Include != "" || ticket.ParamsFile != "" {
+			return Job{}, newJobError(http.StatusBadRequest, "ticket %d: attachments, include and params_file aren't accepted by the server", i)
+		}
+	}
+
+	job := &Job{
+		ID:        newRunID(),
+		Status:    jobQueued,
+		Epic:      req.Epic,
+		DryRun:    req.DryRun,
+		Submitted: time.Now().UTC(),
+		Created:   make([]CreatedIssue, 0),
+		tickets:   req.Tickets,
+	}
+	// The job is registered before it's queued, so the worker never runs
+	// a job the server doesn't know of.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+	select {
+	case s.queue <- job:
+	default:
+		delete(s.jobs, job.ID)
+		return Job{}, newJobError(http.StatusServiceUnavailable, "too many jobs queued")
+	}
+	s.save(job)
+	fmt.Printf("Job %s queued for %s\n", job.ID, job.Epic)
+	return job.snapshot(), nil
+}
+
+// listJobs returns every job, in the order they were submitted, without
+// their output.
+func (s *jobServer) listJobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		listed := job.snapshot()
+		listed.Output = ""
+		jobs = append(jobs, listed)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Submitted.Before(jobs[j].Submitted)
+	})
+	return jobs
+}
+
+// getJob returns the job, with the issues it has created so far.
+func (s *jobServer) getJob(id string) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, newJobError(http.StatusNotFound, "no job %s", id)
+	}
+	if job.Status == jobRunning {
+		s.refresh(job)
+	}
+	return job.snapshot(), nil
+}
+
+// cancelJob stops a job: a queued one is never run, and a running one is
+// interrupted, so it stops before its next issue and releases its locks,
+// leaving behind whichever issues it had created. If it hasn't stopped
+// within jobStopTimeout, its process is killed.
+func (s *jobServer) cancelJob(id string) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, newJobError(http.StatusNotFound, "no job %s", id)
+	}
+	switch job.Status {
+	case jobQueued:
+		job.cancelled = true
+		job.Status = jobCancelled
+		job.Finished = time.Now().UTC()
+		s.save(job)
+	case jobRunning:
+		job.cancelled = true
+		if job.cmd != nil {
+			s.stop(job.cmd)
+		}
+	default:
+		return Job{}, newJobError(http.StatusConflict, "job %s has already %s", id, job.Status)
+	}
+	fmt.Printf("Job %s cancelled\n", job.ID)
+	return job.snapshot(), nil
+}
+
+func (s *jobServer) submit(w http.ResponseWriter, r *http.Request) {
+	var req jobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeServeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	job, err := s.submitJob(req)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (s *jobServer) get(w http.ResponseWriter, id string) {
+	job, err := s.getJob(id)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (s *jobServer) cancel(w http.ResponseWriter, id string) {
+	job, err := s.cancelJob(id)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// stop interrupts the job's process, killing it if it's still running after
+// jobStopTimeout. s.mu must be held.
+func (s *jobServer) stop(cmd *exec.Cmd) {
+	if err := cmd.Process.Signal(os.Interrupt); err != nil {
+		// Windows can't interrupt another process.
+		cmd.Process.Kill()
+		return
+	}
+	time.AfterFunc(jobStopTimeout, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, job := range s.jobs {
+			if job.cmd == cmd {
+				fmt.Printf("Job %s didn't stop within %v; killing it\n", job.ID, jobStopTimeout)
+				cmd.Process.Kill()
+			}
+		}
+	})
+}
+
+func writeServeError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	writeJSON(w, status, map[string]string{"error": fmt.Sprintf(format, args...)})
+}
+
+func writeJobError(w http.ResponseWriter, err error) {
+	if e, ok := err.(*jobError); ok {
+		writeServeError(w, e.status, "%s", e.message)
+		return
+	}
+	writeServeError(w, http.StatusInternalServerError, "%v", err)
+}
+
+func runServe() {
+	dir := *serveJobsDir
+	if dir == "" {
+		dir = path.Join(*stateDir, "jobs")
+	}
+	s := newJobServer(jobArgs(os.Args[1:]), dir)
+	if err := s.load(); err != nil {
+		panic(err)
+	}
+	go s.work()
+
+	if *serveGRPCListen != "" {
+		go func() {
+			if err := serveGRPC(s); err != nil {
+				fmt.Fprintf(os.Stderr, "gRPC server failed: %v\n", err)
+				os.Exit(1)
+			}
+		}()
+	}
+	fmt.Printf("Serving on http://%s\n", *serveListen)
+	if err := http.ListenAndServe(*serveListen, s); err != nil {
+		panic(err)
+	}
+}
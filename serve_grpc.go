@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	serveGRPCListen = serveCmd.Flag(
+		"grpc-listen",
+		"Address to serve the gRPC API on as well, e.g. localhost:8082. Its messages are JSON, under the json content subtype.",
+	).String()
+)
+
+// jobsService is the name of the gRPC service, whose methods are called as
+// /epiccreator.Jobs/<method>.
+const jobsService = "epiccreator.Jobs"
+
+// jsonCodec encodes gRPC messages as JSON, the same as the HTTP API's bodies,
+// so the service needs no generated protobuf code. Clients select it with the
+// json content subtype, as application/grpc+json.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// jobID names the job a Get or Cancel call is about.
+type jobID struct {
+	ID string `json:"id"`
+}
+
+// jobList is the reply to List.
+type jobList struct {
+	Jobs []Job `json:"jobs"`
+}
+
+// listRequest is the empty request of List.
+type listRequest struct{}
+
+// jobsAPI is the gRPC service: the HTTP API's calls, on the same jobs.
+type jobsAPI interface {
+	Submit(ctx context.Context, req *jobRequest) (*Job, error)
+	Get(ctx context.Context, req *jobID) (*Job, error)
+	List(ctx context.Context, req *listRequest) (*jobList, error)
+	Cancel(ctx context.Context, req *jobID) (*Job, error)
+}
+
+// grpcJobs serves the gRPC service from the job server.
+type grpcJobs struct {
+	s *jobServer
+}
+
+func (g grpcJobs) Submit(ctx context.Context, req *jobRequest) (*Job, error) {
+	job, err := g.s.submitJob(*req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &job, nil
+}
+
+func (g grpcJobs) Get(ctx context.Context, req *jobID) (*Job, error) {
+	job, err := g.s.getJob(req.ID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &job, nil
+}
+
+func (g grpcJobs) List(ctx context.Context, req *listRequest) (*jobList, error) {
+	return &jobList{Jobs: g.s.listJobs()}, nil
+}
+
+func (g grpcJobs) Cancel(ctx context.Context, req *jobID) (*Job, error) {
+	job, err := g.s.cancelJob(req.ID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &job, nil
+}
+
+// grpcCodes are the gRPC codes of the HTTP statuses jobErrors have.
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:         codes.InvalidArgument,
+	http.StatusNotFound:           codes.NotFound,
+	http.StatusConflict:           codes.FailedPrecondition,
+	http.StatusServiceUnavailable: codes.ResourceExhausted,
+}
+
+func grpcError(err error) error {
+	if e, ok := err.(*jobError); ok {
+		if code, ok := grpcCodes[e.status]; ok {
+			return status.Error(code, e.message)
+		}
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// unaryJobsMethod describes a method of the service, as protoc would
+// generate it: call is the method, and newRequest its empty request.
+func unaryJobsMethod(
+	name string,
+	newRequest func() interface{},
+	call func(api jobsAPI, ctx context.Context, req interface{}) (interface{}, error),
+) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := newRequest()
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return call(srv.(jobsAPI), ctx, req)
+			}
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + jobsService + "/" + name}
+			return interceptor(ctx, req, info, handler)
+		},
+	}
+}
+
+var jobsServiceDesc = grpc.ServiceDesc{
+	ServiceName: jobsService,
+	HandlerType: (*jobsAPI)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryJobsMethod("Submit", func() interface{} { return new(jobRequest) }, func(api jobsAPI, ctx context.Context, req interface{}) (interface{}, error) {
+			return api.Submit(ctx, req.(*jobRequest))
+		}),
+		unaryJobsMethod("Get", func() interface{} { return new(jobID) }, func(api jobsAPI, ctx context.Context, req interface{}) (interface{}, error) {
+			return api.Get(ctx, req.(*jobID))
+		}),
+		unaryJobsMethod("List", func() interface{} { return new(listRequest) }, func(api jobsAPI, ctx context.Context, req interface{}) (interface{}, error) {
+			return api.List(ctx, req.(*listRequest))
+		}),
+		unaryJobsMethod("Cancel", func() interface{} { return new(jobID) }, func(api jobsAPI, ctx context.Context, req interface{}) (interface{}, error) {
+			return api.Cancel(ctx, req.(*jobID))
+		}),
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// authorizeGRPC requires the --token bearer token in the call's
+// authorization metadata, as the HTTP API requires it in its header.
+func authorizeGRPC(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if *serveToken != "" {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 || values[0] != "Bearer "+*serveToken {
+			return nil, status.Error(codes.Unauthenticated, "missing or wrong bearer token")
+		}
+	}
+	return handler(ctx, req)
+}
+
+// serveGRPC serves the gRPC API on --grpc-listen.
+func serveGRPC(s *jobServer) error {
+	lis, err := net.Listen("tcp", *serveGRPCListen)
+	if err != nil {
+		return err
+	}
+	server := grpc.NewServer(grpc.UnaryInterceptor(authorizeGRPC))
+	server.RegisterService(&jobsServiceDesc, grpcJobs{s})
+	fmt.Printf("Serving gRPC on %s\n", *serveGRPCListen)
+	return server.Serve(lis)
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	statusCmd = kingpin.Command(
+		"status",
+		"List an epic's issues with their status, assignee and story points.",
+	)
+	statusFormat = statusCmd.Flag(
+		"format",
+		"Output format.",
+	).Default("table").Enum("table", "json")
+	statusEpic = statusCmd.Arg("epic", "Key of the epic.").Required().String()
+)
+
+// IssueStatus is an issue in an epic, as the status command shows it.
+type IssueStatus struct {
+	Key      string  `json:"key"`
+	Summary  string  `json:"summary"`
+	Status   string  `json:"status"`
+	Done     bool    `json:"done"`
+	Assignee string  `json:"assignee,omitempty"`
+	Points   float64 `json:"points,omitempty"`
+	// Components are the project components the issue belongs to.
+	Components []string `json:"components,omitempty"`
+}
+
+// epicLister is implemented by trackers which can list the issues in an
+// epic. filter is a query in the tracker's own language narrowing them
+// down, if not empty.
+type epicLister interface {
+	EpicIssues(epic *Epic, filter string) ([]IssueStatus, error)
+}
+
+// epicStatus is an epic's issues, with their roll-ups.
+type epicStatus struct {
+	Epic            string        `json:"epic"`
+	Issues          []IssueStatus `json:"issues"`
+	Done            int           `json:"done"`
+	Total           int           `json:"total"`
+	Points          float64       `json:"points"`
+	PointsRemaining float64       `json:"points_remaining"`
+}
+
+func newEpicStatus(epic *Epic, issues []IssueStatus) epicStatus {
+	status := epicStatus{Epic: epic.Key, Issues: issues, Total: len(issues)}
+	for _, issue := range issues {
+		status.Points += issue.Points
+		if issue.Done {
+			status.Done++
+		} else {
+			status.PointsRemaining += issue.Points
+		}
+	}
+	return status
+}
+
+func printEpicStatus(status epicStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tSTATUS\tASSIGNEE\tPOINTS\tSUMMARY")
+	for _, issue := range status.Issues {
+		points := ""
+		if issue.Points != 0 {
+			points = strconv.FormatFloat(issue.Points, 'g', -1, 64)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", issue.Key, issue.Status, issue.Assignee, points, issue.Summary)
+	}
+	w.Flush()
+	fmt.Printf(
+		"\n%s: %d of %d issue(s) done, %g of %g story points remaining.\n",
+		status.Epic,
+		status.Done,
+		status.Total,
+		status.PointsRemaining,
+		status.Points,
+	)
+}
+
+func runStatus() {
+	tracker, err := newTracker(*backend)
+	if err != nil {
+		panic(err)
+	}
+	lister, ok := tracker.(epicLister)
+	if !ok {
+		panic(fmt.Errorf("backend %s does not support listing an epic's issues", *backend))
+	}
+
+	epic, err := tracker.ResolveEpic(*statusEpic)
+	if err != nil {
+		panic(err)
+	}
+	issues, err := lister.EpicIssues(epic, "")
+	if err != nil {
+		panic(err)
+	}
+
+	status := newEpicStatus(epic, issues)
+	if *statusFormat == "json" {
+		data, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			panic(err)
+		}
+		os.Stdout.Write(append(data, '\n'))
+		return
+	}
+	printEpicStatus(status)
+}
+
+type jiraSearchResult struct {
+	Total  int `json:"total"`
+	Issues []struct {
+		Key    string          `json:"key"`
+		Fields json.RawMessage `json:"fields"`
+	} `json:"issues"`
+}
+
+type jiraStatusFields struct {
+	Summary string `json:"summary"`
+	Status  struct {
+		Name           string `json:"name"`
+		StatusCategory struct {
+			Key string `json:"key"`
+		} `json:"statusCategory"`
+	} `json:"status"`
+	Assignee *struct {
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	} `json:"assignee"`
+	Components []struct {
+		Name string `json:"name"`
+	} `json:"components"`
+}
+
+// epicJQL is JQL which finds the issues in the epic: its children on
+// Cloud, and by the Epic Link elsewhere.
+func epicJQL(key string) string {
+	if jiraCloud {
+		return fmt.Sprintf(`parent = %s`, key)
+	}
+	return fmt.Sprintf(`"Epic Link" = %s`, key)
+}
+
+// EpicIssues searches for the issues in the epic. filter is JQL.
+func (t *jiraTracker) EpicIssues(epic *Epic, filter string) ([]IssueStatus, error) {
+	jql := epicJQL(epic.Key)
+	if filter != "" {
+		jql += fmt.Sprintf(" AND (%s)", filter)
+	}
+	return t.SearchIssues(jql + " ORDER BY rank")
+}
+
+// SearchIssues finds the issues matching the JQL, a page at a time. Story
+// points are left out if there's no story points field.
+func (t *jiraTracker) SearchIssues(jql string) ([]IssueStatus, error) {
+	pointsField := *storyPointsFieldID
+	if pointsField == "" {
+		if all, err := t.allFields(); err == nil {
+			pointsField, _ = findStoryPointsField(all)
+		}
+	}
+
+	fields := "summary,status,assignee,components"
+	if pointsField != "" {
+		fields += "," + pointsField
+	}
+
+	issues := make([]IssueStatus, 0)
+	for {
+		query := url.Values{}
+		query.Set("jql", jql)
+		query.Set("fields", fields)
+		query.Set("startAt", strconv.Itoa(len(issues)))
+		query.Set("maxResults", "100")
+		req, err := t.client.NewRequest("GET", "rest/api/2/search?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result jiraSearchResult
+		resp, err := t.client.Do(req, &result)
+		if err != nil {
+			return nil, jiraAPIRequestErrorHandler(resp, err)
+		}
+
+		for _, found := range result.Issues {
+			var f jiraStatusFields
+			if err := json.Unmarshal(found.Fields, &f); err != nil {
+				return nil, err
+			}
+			var raw map[string]json.RawMessage
+			if err := json.Unmarshal(found.Fields, &raw); err != nil {
+				return nil, err
+			}
+
+			issue := IssueStatus{
+				Key:     found.Key,
+				Summary: f.Summary,
+				Status:  f.Status.Name,
+				Done:    f.Status.StatusCategory.Key == "done",
+			}
+			if f.Assignee != nil {
+				issue.Assignee = f.Assignee.DisplayName
+			}
+			for _, component := range f.Components {
+				issue.Components = append(issue.Components, component.Name)
+			}
+			if points, ok := raw[pointsField]; ok {
+				// Unset points are null, which leaves them at 0.
+				json.Unmarshal(points, &issue.Points)
+			}
+			issues = append(issues, issue)
+		}
+		if len(result.Issues) == 0 || len(issues) >= result.Total {
+			return issues, nil
+		}
+	}
+}
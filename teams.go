@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type jiraTeam struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+// teamID resolves an Advanced Roadmaps team, given by name or ID, to the
+// ID the Team field takes. Teams are looked up once per run.
+func (t *jiraTracker) teamID(team string) (string, error) {
+	if _, err := strconv.ParseInt(team, 10, 64); err == nil || isUUID(team) {
+		return team, nil
+	}
+	t.mu.Lock()
+	id, ok := t.teams[team]
+	t.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	err := cached(cacheKey("team", team), &id, false, func() error {
+		var err error
+		id, err = t.findTeam(team)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.teams == nil {
+		t.teams = make(map[string]string)
+	}
+	t.teams[team] = id
+	return id, nil
+}
+
+// findTeam finds the ID of the one team with the name.
+func (t *jiraTracker) findTeam(team string) (string, error) {
+	body := map[string]interface{}{
+		"query":       team,
+		"maxResults":  100,
+		"excludedIds": []int64{},
+	}
+	req, err := t.client.NewRequest("POST", "rest/teams/1.0/teams/find", body)
+	if err != nil {
+		return "", err
+	}
+
+	var found struct {
+		Teams []jiraTeam `json:"teams"`
+	}
+	resp, err := t.client.Do(req, &found)
+	if err != nil {
+		return "", jiraAPIRequestErrorHandler(resp, err)
+	}
+
+	// The search also matches part of the name.
+	matches := make([]jiraTeam, 0, 1)
+	for _, candidate := range found.Teams {
+		if strings.EqualFold(candidate.Title, team) {
+			matches = append(matches, candidate)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no team is named %q", team)
+	case 1:
+	default:
+		return "", fmt.Errorf("more than one team is named %q", team)
+	}
+
+	return strconv.FormatInt(matches[0].ID, 10), nil
+}
+
+// setTeam sets the Team field to the team, given by name or ID.
+func (t *jiraTracker) setTeam(team string, unknowns map[string]interface{}) error {
+	field, err := t.fieldID("Team")
+	if err != nil {
+		return err
+	}
+	if field == "" {
+		return fmt.Errorf("there's no Team field to set team %q with", team)
+	}
+	id, err := t.teamID(team)
+	if err != nil {
+		return err
+	}
+	unknowns[field] = id
+	return nil
+}
+
+// isUUID reports whether s looks like a UUID, which is how Jira Cloud
+// identifies teams.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, r := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+				return false
+			}
+		}
+	}
+	return true
+}
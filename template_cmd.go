@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	templateCmd = kingpin.Command(
+		"template",
+		"Work with summary and description templates.",
+	)
+	templateTestCmd = templateCmd.Command(
+		"test",
+		"Render the templates against sample tickets, reporting missing params and summaries which are too long.",
+	)
+	templateTestData = templateTestCmd.Flag(
+		"data",
+		"Tickets file of sample tickets to render.",
+	).Default(
+		path.Join(workdir, "tickets.json"),
+	).String()
+	templateTestSummary = templateTestCmd.Flag(
+		"summary-template",
+		"Path to the summary template.",
+	).Default(
+		path.Join(workdir, "summary.jira.tmpl"),
+	).ExistingFile()
+	templateTestDescription = templateTestCmd.Flag(
+		"description-template",
+		"Path to the description template.",
+	).Default(
+		path.Join(workdir, "description.jira.tmpl"),
+	).ExistingFile()
+	templateTestEpic = templateTestCmd.Flag(
+		"epic",
+		"Epic key to render as the epic param of tickets which don't name one.",
+	).Default("EPIC-1").String()
+)
+
+// renderChecked renders tmpl, failing on missing params whether they are
+// caught by missingkey=error or rendered as "<no value>".
+func renderChecked(tmpl *template.Template, ctx ticketContext) (string, error) {
+	buf := bytes.NewBufferString("")
+	if err := tmpl.Execute(buf, ctx); err != nil {
+		return "", err
+	}
+	return buf.String(), checkNoValue(buf.String())
+}
+
+// testTicket renders one ticket, returning its problems.
+func testTicket(summaryTemplate, descriptionTemplate *template.Template, ticket Ticket) []string {
+	problems := make([]string, 0)
+
+	ctx := newTicketContext(ticket, &Epic{Key: fmt.Sprint(ticket.Params["epic"])})
+	summary, err := renderChecked(summaryTemplate, ctx)
+	if err != nil {
+		problems = append(problems, "summary: "+err.Error())
+	}
+	if n := len([]rune(summary)); n > jiraSummaryLimit {
+		problems = append(problems, fmt.Sprintf("summary is %d characters, over JIRA's limit of %d", n, jiraSummaryLimit))
+	}
+	if strings.Contains(strings.TrimSpace(summary), "\n") {
+		problems = append(problems, "summary spans more than one line")
+	}
+
+	if _, err := renderChecked(descriptionTemplate, ctx); err != nil {
+		problems = append(problems, "description: "+err.Error())
+	}
+
+	if ticket.Comment != "" {
+		comment, err := parseComment(ticket.Comment)
+		if err == nil {
+			_, err = renderChecked(comment.Option("missingkey=error"), ctx)
+		}
+		if err != nil {
+			problems = append(problems, "comment: "+err.Error())
+		}
+	}
+	return problems
+}
+
+func runTemplateTest() {
+	summaryTemplate, err := loadTemplate(*templateTestSummary)
+	if err != nil {
+		panic(err)
+	}
+	descriptionTemplate, err := loadTemplate(*templateTestDescription)
+	if err != nil {
+		panic(err)
+	}
+	summaryTemplate.Option("missingkey=error")
+	descriptionTemplate.Option("missingkey=error")
+
+	tickets, err := loadTickets(*templateTestData)
+	if err != nil {
+		panic(err)
+	}
+
+	failed := 0
+	for i, ticket := range tickets {
+		if ticket.Params == nil {
+			ticket.Params = make(map[string]interface{})
+		}
+		if _, ok := ticket.Params["epic"]; !ok {
+			ticket.Params["epic"] = *templateTestEpic
+			if ticket.Epic != "" {
+				ticket.Params["epic"] = ticket.Epic
+			}
+		}
+		ticket.Index = i + 1
+		ticket.Total = len(tickets)
+
+		problems := testTicket(summaryTemplate, descriptionTemplate, ticket)
+		if len(problems) == 0 {
+			continue
+		}
+		failed++
+		for _, problem := range problems {
+			fmt.Printf("ticket %d: %s\n", i, problem)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("%d of %d ticket(s) failed.\n", failed, len(tickets))
+		os.Exit(1)
+	}
+	fmt.Printf("All %d ticket(s) rendered cleanly.\n", len(tickets))
+}
@@ -0,0 +1,24 @@
+package main
+
+// ticketContext is what ticket templates are executed with: the ticket,
+// and the epic its issue goes in.
+type ticketContext struct {
+	Ticket
+	// Epic is the epic in full, in place of the ticket's epic field. It
+	// renders as its key, as that did.
+	Epic *Epic
+	// Globals are the run's globals, in place of the ticket's.
+	Globals map[string]interface{}
+}
+
+func newTicketContext(ticket Ticket, epic *Epic) ticketContext {
+	if epic == nil {
+		epic = &Epic{Key: ticket.Epic}
+	}
+	return ticketContext{Ticket: ticket, Epic: epic, Globals: globals}
+}
+
+// String renders the epic in templates as its key.
+func (e *Epic) String() string {
+	return e.Key
+}
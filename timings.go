@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// phaseTimer times the phases of a run, one after another.
+type phaseTimer struct {
+	phases  []string
+	elapsed map[string]time.Duration
+	current string
+	started time.Time
+}
+
+// timings times the phases of the current run.
+var timings = &phaseTimer{elapsed: make(map[string]time.Duration)}
+
+// Start ends the current phase, if any, and starts the named one. A phase
+// started more than once has the time of each added up.
+func (t *phaseTimer) Start(phase string) {
+	t.Stop()
+	if _, ok := t.elapsed[phase]; !ok {
+		t.phases = append(t.phases, phase)
+		t.elapsed[phase] = 0
+	}
+	t.current = phase
+	t.started = time.Now()
+}
+
+// Stop ends the current phase.
+func (t *phaseTimer) Stop() {
+	if t.current == "" {
+		return
+	}
+	t.elapsed[t.current] += time.Since(t.started)
+	t.current = ""
+}
+
+// Print prints how long each phase took, and how long each issue took to
+// create on average.
+func (t *phaseTimer) Print(created int) {
+	t.Stop()
+	if len(t.phases) == 0 {
+		return
+	}
+
+	fmt.Println("Timings:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	var total time.Duration
+	for _, phase := range t.phases {
+		fmt.Fprintf(w, "  %s\t%.2fs\n", phase, t.elapsed[phase].Seconds())
+		total += t.elapsed[phase]
+	}
+	fmt.Fprintf(w, "  total\t%.2fs\n", total.Seconds())
+	w.Flush()
+	if created > 0 {
+		fmt.Printf(
+			"Created %d issue(s) in %.2fs each on average.\n",
+			created,
+			t.elapsed["creation"].Seconds()/float64(created),
+		)
+	}
+}
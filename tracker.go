@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kinds of link which can be made between issues. How each is represented
+// is up to the tracker.
+const (
+	linkRelates = "relates"
+	linkBlocks  = "blocks"
+)
+
+// Epic is the container created issues are attached to. In JIRA this is an
+// Epic; other trackers have their own equivalent, such as a milestone or a
+// tracking issue.
+type Epic struct {
+	ID  string
+	Key string
+	URL string
+	// Project is the project the epic is in, where trackers have one.
+	Project string
+	// Status is the epic's workflow status, and Closed whether that means
+	// work on it is finished.
+	Status string
+	Closed bool
+
+	// The epic's details, for templates, where the tracker has them.
+	Summary     string
+	Description string
+	Labels      []string
+	// DueDate is when the epic is due, as YYYY-MM-DD.
+	DueDate string
+	// Fields are the epic's other fields, by name.
+	Fields map[string]interface{}
+}
+
+// Issue is a ticket which has been rendered and is ready to be created.
+type Issue struct {
+	// Index is the position of the ticket in the tickets file.
+	Index  int
+	Ticket Ticket
+	// Epic is the epic the issue is to be created in.
+	Epic        *Epic
+	Summary     string
+	Description string
+	// Comment is the rendered initial comment, if the ticket has one.
+	Comment string
+	// Fields are set on the issue, by name or ID, from --fields-template.
+	Fields map[string]interface{}
+}
+
+// indexIssues maps the issues by the index of their ticket, which is what
+// CreatedIssue records. Tickets which were skipped leave gaps, so the index
+// isn't the issue's position in the list.
+func indexIssues(issues []Issue) map[int]Issue {
+	byIndex := make(map[int]Issue, len(issues))
+	for _, issue := range issues {
+		byIndex[issue.Index] = issue
+	}
+	return byIndex
+}
+
+// Tracker is an issue tracker which epic-creator can create issues in.
+//
+// To add a backend, implement Tracker and call registerTracker from an init
+// function in the backend's file.
+type Tracker interface {
+	// ResolveEpic looks up the epic named on the command line.
+	ResolveEpic(name string) (*Epic, error)
+	// CreateIssues creates issues in the epic, recording each one in run as
+	// it is created.
+	CreateIssues(epic *Epic, issues []Issue, run *Run) error
+	// LinkIssues links two previously created issues, such that from
+	// <kind> to, e.g. "from blocks to".
+	LinkIssues(from string, to string, kind string) error
+	// DeleteIssue removes a previously created issue.
+	DeleteIssue(key string) error
+	// CloseIssue closes a previously created issue, using the named
+	// transition where the tracker has a workflow.
+	CloseIssue(key string, transition string) error
+}
+
+// trackerFactory constructs a Tracker from the command line flags.
+type trackerFactory func() Tracker
+
+var trackers = make(map[string]trackerFactory)
+
+func registerTracker(name string, factory trackerFactory) {
+	if _, ok := trackers[name]; ok {
+		panic(fmt.Sprintf("tracker %q registered twice", name))
+	}
+	trackers[name] = factory
+}
+
+func trackerNames() []string {
+	names := make([]string, 0, len(trackers))
+	for name := range trackers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newTracker(backend string) (Tracker, error) {
+	factory, ok := trackers[backend]
+	if !ok {
+		return nil, fmt.Errorf(
+			"unknown backend %q, expected one of: %s",
+			backend,
+			strings.Join(trackerNames(), ", "),
+		)
+	}
+	tracker := factory()
+	lookupTracker = tracker
+	return tracker, nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+)
+
+// transitioner is implemented by trackers with a workflow which issues can
+// be moved through.
+type transitioner interface {
+	TransitionIssue(key string, name string) error
+}
+
+// transitionCreated moves the issue through the ticket's transition, or
+// --transition if the ticket doesn't name one.
+func transitionCreated(tracker Tracker, run *Run, issue Issue, created CreatedIssue) error {
+	name := issue.Ticket.Transition
+	if name == "" {
+		name = *transition
+	}
+	if name == "" {
+		return nil
+	}
+
+	t, ok := tracker.(transitioner)
+	if !ok {
+		return fmt.Errorf("backend %s does not support transitions", *backend)
+	}
+	if err := t.TransitionIssue(created.Key, name); err != nil {
+		return err
+	}
+	fmt.Printf("Transitioned %s: %s\n", created.Key, name)
+	return nil
+}
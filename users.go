@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// userResolver is implemented by trackers which can look users up by
+// email address or display name.
+type userResolver interface {
+	// ResolveUser returns how the tracker refers to the user, which may
+	// already be how it refers to them.
+	ResolveUser(user string) (string, error)
+}
+
+// resolveTicketUsers resolves the users the ticket names, before anything
+// is created, so an unknown or ambiguous user fails the run up front.
+func resolveTicketUsers(tracker Tracker, ticket *Ticket) error {
+	r, ok := tracker.(userResolver)
+	if !ok {
+		return nil
+	}
+
+	var err error
+	if ticket.Assignee, err = resolveUser(r, ticket.Assignee); err != nil {
+		return fmt.Errorf("assignee: %v", err)
+	}
+	if ticket.Reporter, err = resolveUser(r, ticket.Reporter); err != nil {
+		return fmt.Errorf("reporter: %v", err)
+	}
+	watchers := make([]string, len(ticket.Watchers))
+	for i, watcher := range ticket.Watchers {
+		if watchers[i], err = resolveUser(r, watcher); err != nil {
+			return fmt.Errorf("watcher: %v", err)
+		}
+	}
+	ticket.Watchers = watchers
+	return nil
+}
+
+func resolveUser(r userResolver, user string) (string, error) {
+	if user == "" {
+		return "", nil
+	}
+	return r.ResolveUser(user)
+}
+
+type jiraUserResult struct {
+	AccountID    string `json:"accountId"`
+	Name         string `json:"name"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+	Active       bool   `json:"active"`
+}
+
+// id is how JIRA refers to the user: by accountId on Cloud, and by username
+// elsewhere.
+func (u jiraUserResult) id() string {
+	if jiraCloud {
+		return u.AccountID
+	}
+	return u.Name
+}
+
+// ResolveUser looks up users given by email address or display name, which
+// have an @ or a space in them, through the user search API. Anything else
+// is taken to be a username, or an accountId on Cloud. Lookups are cached,
+// on disk too with --metadata-cache.
+func (t *jiraTracker) ResolveUser(user string) (string, error) {
+	if !strings.ContainsAny(user, "@ ") {
+		return user, nil
+	}
+	t.mu.Lock()
+	id, ok := t.users[user]
+	t.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	err := cached(cacheKey("user", user), &id, false, func() error {
+		var err error
+		found, err := t.searchUser(user)
+		id = found.id()
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.users == nil {
+		t.users = make(map[string]string)
+	}
+	t.users[user] = id
+	return id, nil
+}
+
+// searchUser finds the one user matching an email address or display name.
+func (t *jiraTracker) searchUser(user string) (jiraUserResult, error) {
+	var none jiraUserResult
+	query := url.Values{}
+	api := "rest/api/2/user/search"
+	if jiraCloud {
+		api = "rest/api/3/user/search"
+		query.Set("query", user)
+	} else {
+		query.Set("username", user)
+	}
+	req, err := t.client.NewRequest("GET", api+"?"+query.Encode(), nil)
+	if err != nil {
+		return none, err
+	}
+
+	found := make([]jiraUserResult, 0)
+	resp, err := t.client.Do(req, &found)
+	if err != nil {
+		return none, jiraAPIRequestErrorHandler(resp, err)
+	}
+
+	// Prefer exact matches, as the search also matches prefixes.
+	exact := make([]jiraUserResult, 0, len(found))
+	for _, u := range found {
+		if strings.EqualFold(u.EmailAddress, user) || strings.EqualFold(u.DisplayName, user) {
+			exact = append(exact, u)
+		}
+	}
+	if len(exact) > 0 {
+		found = exact
+	}
+
+	switch len(found) {
+	case 0:
+		return none, fmt.Errorf("no user matches %q", user)
+	case 1:
+		return found[0], nil
+	}
+	candidates := make([]string, len(found))
+	for i, u := range found {
+		candidates[i] = fmt.Sprintf("%s (%s)", u.DisplayName, u.id())
+	}
+	return none, fmt.Errorf("%q matches more than one user: %s", user, strings.Join(candidates, ", "))
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// These are set when building a release:
+//
+//	go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildDate = "unknown"
+	// goJiraVersion is the go-jira commit pinned in glide.lock.
+	goJiraVersion = "acbc88d59582a4885dbd82fd775caa7c15245171"
+)
+
+var (
+	versionCmd = kingpin.Command(
+		"version",
+		"Print the version of epic-creator and what it was built from.",
+	)
+)
+
+// toolVersion is the version recorded on runs and the issues they create.
+func toolVersion() string {
+	return fmt.Sprintf("%s (%s)", version, commit)
+}
+
+func runVersion() {
+	fmt.Printf("epic-creator %s\n", version)
+	fmt.Printf("commit:  %s\n", commit)
+	fmt.Printf("built:   %s\n", buildDate)
+	fmt.Printf("go-jira: %s\n", goJiraVersion)
+	fmt.Printf("go:      %s\n", runtime.Version())
+}
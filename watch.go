@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+var (
+	watch = createCmd.Flag(
+		"watch",
+		"Keep running, and whenever the tickets file or templates change, create the new tickets and update the descriptions of those already created.",
+	).Bool()
+	watchInterval = createCmd.Flag(
+		"watch-interval",
+		"How often --watch checks for changes.",
+	).Default("2s").Duration()
+)
+
+// watchedIssue is an issue created while watching.
+type watchedIssue struct {
+	Key  string
+	Hash string
+}
+
+// watchedIssues are the issues created while watching, by summary, which
+// is what identifies a ticket from one change to the next.
+type watchedIssues struct {
+	bySummary map[string]watchedIssue
+}
+
+// watched is set while watching.
+var watched *watchedIssues
+
+// Sync updates the descriptions of issues which were already created and
+// have changed since, returning the rest, which are still to be created.
+func (w *watchedIssues) Sync(tracker Tracker, issues []Issue) ([]Issue, error) {
+	remaining := make([]Issue, 0, len(issues))
+	for _, issue := range issues {
+		existing, ok := w.bySummary[issue.Summary]
+		if !ok {
+			remaining = append(remaining, issue)
+			continue
+		}
+
+		hash := contentHash(issue)
+		if hash == existing.Hash {
+			continue
+		}
+		if *dryRun {
+			fmt.Printf("Would update %s: %s\n", existing.Key, issue.Summary)
+			continue
+		}
+
+		d, ok := tracker.(describer)
+		if !ok {
+			return nil, fmt.Errorf("backend %s does not support updating descriptions", *backend)
+		}
+		description := issue.Description
+		if *backend == "jira" && *descriptionFormat == "markdown" {
+			description = markdownToWiki(description)
+		}
+		if err := d.SetDescription(existing.Key, description); err != nil {
+			return nil, err
+		}
+		w.bySummary[issue.Summary] = watchedIssue{Key: existing.Key, Hash: hash}
+		fmt.Printf("Updated %s: %s\n", existing.Key, issue.Summary)
+	}
+	return remaining, nil
+}
+
+// Record adds the issues the run created.
+func (w *watchedIssues) Record(issues []Issue, run *Run) {
+	byIndex := indexIssues(issues)
+	for _, created := range run.Created {
+		if issue, ok := byIndex[created.Ticket]; ok {
+			w.bySummary[issue.Summary] = watchedIssue{Key: created.Key, Hash: contentHash(issue)}
+		}
+	}
+}
+
+// watchedFiles are the files a change to which starts another run.
+func watchedFiles() []string {
+	files := []string{*summaryTemplatePath, *descriptionTemplatePath}
+	if *ticketsFilePath != "-" {
+		files = append(files, *ticketsFilePath)
+	}
+	return files
+}
+
+func modTimes(files []string) map[string]time.Time {
+	times := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			times[file] = info.ModTime()
+		}
+	}
+	return times
+}
+
+// waitForChange polls the files until one of them changes.
+func waitForChange(files []string, since map[string]time.Time) {
+	for {
+		time.Sleep(*watchInterval)
+		now := modTimes(files)
+		for _, file := range files {
+			if !now[file].Equal(since[file]) {
+				fmt.Printf("%s changed.\n", file)
+				return
+			}
+		}
+	}
+}
+
+// runCreateRecovering runs create, reporting rather than dying of its
+// failure, for modes which run it again later.
+func runCreateRecovering() {
+	timings = &phaseTimer{elapsed: make(map[string]time.Duration)}
+	defer func() {
+		if r := recover(); r != nil {
+			if f, ok := r.(runFailure); ok {
+				printFailed(f.err)
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Run failed: %v\n", r)
+		}
+	}()
+	runCreate()
+}
+
+// runWatch runs create, then again each time the watched files change. A
+// failed run is reported, and the next change tries again.
+func runWatch() {
+	if *ticketsFilePath == "-" {
+		panic(fmt.Errorf("--watch needs a tickets file, not stdin"))
+	}
+
+	watched = &watchedIssues{bySummary: make(map[string]watchedIssue)}
+	files := watchedFiles()
+	for {
+		times := modTimes(files)
+		runCreateRecovering()
+		fmt.Printf("Watching %d file(s) for changes.\n", len(files))
+		waitForChange(files, times)
+	}
+}
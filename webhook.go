@@ -0,0 +1,48 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+)
+
+// signatureHeader carries the HMAC of a report webhook's body.
+const signatureHeader = "X-Epic-Creator-Signature"
+
+// runReport is the body of a report webhook: the run record, plus how long
+// the run and each of its phases took.
+type runReport struct {
+	*Run
+	DurationSeconds float64     `json:"duration_seconds"`
+	Phases          []phaseTime `json:"phases"`
+}
+
+// sign returns the signature of body under secret, as sent in
+// signatureHeader.
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// postReport POSTs the run's report to url. If secret is set, the body's
+// HMAC-SHA256 is sent in signatureHeader so the receiver can verify it.
+func postReport(url string, secret string, run *Run) error {
+	report := runReport{
+		Run:             run,
+		DurationSeconds: run.Finished.Sub(run.Started).Seconds(),
+		Phases:          timings.Phases(),
+	}
+	data, err := json.Marshal(&report)
+	if err != nil {
+		return err
+	}
+
+	header := make(http.Header)
+	if secret != "" {
+		header.Set(signatureHeader, sign(secret, data))
+	}
+	return postBody(url, data, header)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// A reader for the subset of JIRA wiki markup which markdownToWiki produces,
+// and which people typically write by hand: headings, paragraphs, lists,
+// {code} and {noformat} blocks, bq. quotes, rules, tables, emphasis, inline
+// code and links. Other macros are passed through as text.
+
+var (
+	wikiHeading  = regexp.MustCompile(`^\s*h([1-6])\.\s+(.*)$`)
+	wikiCode     = regexp.MustCompile(`^\s*\{(code|noformat)(?::([^}|]*))?[^}]*\}\s*$`)
+	wikiQuote    = regexp.MustCompile(`^\s*bq\.\s+(.*)$`)
+	wikiRule     = regexp.MustCompile(`^\s*-{4,}\s*$`)
+	wikiListItem = regexp.MustCompile(`^\s*([*#-]+)\s+(.*)$`)
+)
+
+func splitWikiTableRow(line string) ([]string, bool) {
+	line = strings.TrimSpace(line)
+	sep := "|"
+	if strings.HasPrefix(line, "||") {
+		sep = "||"
+	}
+	line = strings.TrimPrefix(line, sep)
+	line = strings.TrimSuffix(line, sep)
+
+	cells := strings.Split(line, sep)
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells, sep == "||"
+}
+
+// parseWiki reads a JIRA wiki markup document into blocks. Line breaks
+// within a paragraph are kept, as they are significant in wiki markup.
+func parseWiki(src string) []block {
+	lines := strings.Split(strings.Replace(src, "\r\n", "\n", -1), "\n")
+	blocks := make([]block, 0)
+	var open *block
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if m := wikiCode.FindStringSubmatch(line); m != nil {
+			end := "{" + m[1] + "}"
+			body := make([]string, 0)
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != end; i++ {
+				body = append(body, lines[i])
+			}
+			blocks = append(blocks, block{kind: blockCode, lang: m[2], text: strings.Join(body, "\n")})
+			open = nil
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			open = nil
+			continue
+		}
+
+		if m := wikiHeading.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, block{kind: blockHeading, level: int(m[1][0] - '0'), text: m[2]})
+			open = nil
+			continue
+		}
+
+		if wikiRule.MatchString(line) {
+			blocks = append(blocks, block{kind: blockRule})
+			open = nil
+			continue
+		}
+
+		if m := wikiListItem.FindStringSubmatch(line); m != nil {
+			markers := m[1]
+			ordered := markers[len(markers)-1] == '#'
+			blocks = append(blocks, block{kind: blockListItem, level: len(markers), ordered: ordered, text: m[2]})
+			open = &blocks[len(blocks)-1]
+			continue
+		}
+
+		if strings.HasPrefix(strings.TrimSpace(line), "|") {
+			cells, header := splitWikiTableRow(line)
+			blocks = append(blocks, block{kind: blockTableRow, header: header, cells: cells})
+			open = nil
+			continue
+		}
+
+		if m := wikiQuote.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, block{kind: blockQuote, text: m[1]})
+			open = nil
+			continue
+		}
+
+		if open != nil {
+			open.text += "\n" + line
+			continue
+		}
+		blocks = append(blocks, block{kind: blockParagraph, text: line})
+		open = &blocks[len(blocks)-1]
+	}
+	return blocks
+}
+
+var wikiInlineDelims = []struct {
+	delim byte
+	kind  spanKind
+}{
+	{'*', spanStrong},
+	{'_', spanEm},
+	{'-', spanStrike},
+}
+
+// wikiClose finds the end of a wiki emphasis span opened at s[0], which must
+// be followed by non-space and closed by the delimiter after non-space at
+// the end of a word.
+func wikiClose(s string, delim byte) int {
+	if len(s) < 3 || s[1] == ' ' {
+		return -1
+	}
+	for j := 2; j < len(s); j++ {
+		if s[j] == delim && s[j-1] != ' ' && (j+1 == len(s) || !isWordByte(s[j+1])) {
+			return j
+		}
+	}
+	return -1
+}
+
+// parseWikiInline reads JIRA wiki inline content into spans.
+func parseWikiInline(s string) []span {
+	spans := make([]span, 0)
+	text := ""
+	flush := func() {
+		if text != "" {
+			spans = append(spans, span{kind: spanText, text: text})
+			text = ""
+		}
+	}
+
+	for i := 0; i < len(s); {
+		rest := s[i:]
+
+		if rest[0] == '\\' && len(rest) > 1 {
+			text += rest[1:2]
+			i += 2
+			continue
+		}
+
+		if strings.HasPrefix(rest, "{{") {
+			if end := strings.Index(rest[2:], "}}"); end >= 0 {
+				flush()
+				spans = append(spans, span{kind: spanCode, text: rest[2 : end+2]})
+				i += end + 4
+				continue
+			}
+		}
+
+		if rest[0] == '[' {
+			if end := strings.Index(rest, "]"); end > 0 {
+				flush()
+				target := rest[1:end]
+				label := target
+				if bar := strings.LastIndex(target, "|"); bar >= 0 {
+					label, target = target[:bar], target[bar+1:]
+				}
+				spans = append(spans, span{kind: spanLink, url: target, children: parseWikiInline(label)})
+				i += end + 1
+				continue
+			}
+		}
+
+		if rest[0] == '!' {
+			if end := strings.Index(rest[1:], "!"); end > 0 && !strings.Contains(rest[1:end+1], " ") {
+				flush()
+				spans = append(spans, span{kind: spanImage, url: rest[1 : end+1]})
+				i += end + 2
+				continue
+			}
+		}
+
+		matched := false
+		if i == 0 || !isWordByte(s[i-1]) {
+			for _, d := range wikiInlineDelims {
+				if rest[0] != d.delim {
+					continue
+				}
+				end := wikiClose(rest, d.delim)
+				if end < 0 {
+					break
+				}
+				flush()
+				spans = append(spans, span{kind: d.kind, children: parseWikiInline(rest[1:end])})
+				i += end + 1
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+
+		text += rest[:1]
+		i++
+	}
+	flush()
+	return spans
+}
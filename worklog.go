@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+var (
+	defaultEstimate = createCmd.Flag(
+		"default-estimate",
+		"Original time estimate for tickets without one, e.g. 1d.",
+	).String()
+)
+
+// Worklog is work to log against an issue once it's created, for effort
+// spent before the issue existed.
+type Worklog struct {
+	// TimeSpent is in JIRA's duration format (e.g. "3h 30m").
+	TimeSpent string `json:"time_spent"`
+	// Started is when the work started, as YYYY-MM-DD. It defaults to
+	// when the issue is created.
+	Started string `json:"started,omitempty"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// worklogger is implemented by trackers which can log work on issues.
+type worklogger interface {
+	// AddWorklog logs the work, leaving the remaining estimate alone if
+	// keepRemaining is set.
+	AddWorklog(key string, worklog Worklog, keepRemaining bool) error
+}
+
+// timeTracking is the ticket's time tracking fields, with --default-estimate
+// filled in, or nil if it has none.
+func timeTracking(ticket Ticket) map[string]string {
+	estimate := ticket.Estimate
+	if estimate == "" {
+		estimate = *defaultEstimate
+	}
+	if estimate == "" && ticket.RemainingEstimate == "" {
+		return nil
+	}
+
+	tracking := make(map[string]string, 2)
+	if estimate != "" {
+		tracking["originalEstimate"] = estimate
+	}
+	if ticket.RemainingEstimate != "" {
+		tracking["remainingEstimate"] = ticket.RemainingEstimate
+	}
+	return tracking
+}
+
+// logWork logs the ticket's worklog, if it has one. A remaining estimate
+// given on the ticket is kept as it is, rather than reduced by the work.
+func logWork(tracker Tracker, run *Run, issue Issue, created CreatedIssue) error {
+	worklog := issue.Ticket.Worklog
+	if worklog == nil {
+		return nil
+	}
+
+	w, ok := tracker.(worklogger)
+	if !ok {
+		return fmt.Errorf("backend %s does not support worklogs", *backend)
+	}
+	if err := w.AddWorklog(created.Key, *worklog, issue.Ticket.RemainingEstimate != ""); err != nil {
+		return err
+	}
+	fmt.Printf("Logged %s on %s\n", worklog.TimeSpent, created.Key)
+	return nil
+}
+
+// AddWorklog logs work on the issue.
+func (t *jiraTracker) AddWorklog(key string, worklog Worklog, keepRemaining bool) error {
+	body := map[string]interface{}{"timeSpent": worklog.TimeSpent}
+	if worklog.Comment != "" {
+		body["comment"] = worklog.Comment
+	}
+	if worklog.Started != "" {
+		started, err := time.ParseInLocation("2006-01-02", worklog.Started, time.Local)
+		if err != nil {
+			return fmt.Errorf("worklog started %q should be YYYY-MM-DD", worklog.Started)
+		}
+		// JIRA wants milliseconds and a zone without a colon.
+		body["started"] = started.Format("2006-01-02T15:04:05.000-0700")
+	}
+
+	api := "rest/api/2/issue/" + key + "/worklog"
+	if keepRemaining {
+		api += "?" + url.Values{"adjustEstimate": {"leave"}}.Encode()
+	}
+	req, err := t.client.NewRequest("POST", api, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Do(req, nil)
+	if err != nil {
+		return jiraAPIRequestErrorHandler(resp, err)
+	}
+	return nil
+}